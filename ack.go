@@ -0,0 +1,50 @@
+package eventbus
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type ackKey struct{}
+
+// an ackState 一次 SendAck 的确认，任一订阅确认即可
+type ackState struct {
+	once sync.Once
+	done chan struct{}
+}
+
+// SendAck 调用事件并等待至少一个订阅确认，订阅需要声明 context.Context 参数并调用 Ack(ctx)
+// timeout 内没有订阅确认时返回 ErrNoAck，事件依旧会被调用。用于关键事件需要送达确认的场景
+func (p *EventBus) SendAck(eventKey string, timeout time.Duration, args ...interface{}) error {
+	s := &sender{key: eventKey, args: args, ack: &ackState{done: make(chan struct{})}}
+	if err := p.send(s); err != nil {
+		return err
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-s.ack.done:
+		return nil
+	case <-timer.C:
+		return ErrNoAck
+	}
+}
+
+// Ack 确认 ctx 所属的 SendAck 发送，ctx 为订阅注入的 context，不属于 SendAck 时返回 false
+func Ack(ctx context.Context) bool {
+	state := ackOf(ctx)
+	if state == nil {
+		return false
+	}
+	state.once.Do(func() { close(state.done) })
+	return true
+}
+
+func ackOf(ctx context.Context) *ackState {
+	if ctx == nil {
+		return nil
+	}
+	state, _ := ctx.Value(ackKey{}).(*ackState)
+	return state
+}
@@ -0,0 +1,38 @@
+package eventbus
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventBus_SendAck(t *testing.T) {
+	events := New()
+	defer events.Close()
+	assert.NoError(t, events.On("critical", func(ctx context.Context, n int) {
+		assert.True(t, Ack(ctx))
+	}))
+	assert.NoError(t, events.On("critical", func(n int) {}))
+	assert.NoError(t, events.SendAck("critical", time.Second, 1))
+
+	// 没有订阅确认
+	assert.NoError(t, events.On("silent", func(ctx context.Context) {}))
+	assert.Equal(t, ErrNoAck, events.SendAck("silent", 20*time.Millisecond))
+	assert.Equal(t, ErrNotFound, events.SendAck("missing", time.Second))
+}
+
+func TestAck_FollowUp(t *testing.T) {
+	events := New()
+	defer events.Close()
+	acked := make(chan bool, 1)
+	assert.NoError(t, events.On("child", func(ctx context.Context) { acked <- Ack(ctx) }))
+	assert.NoError(t, events.On("parent", func(ctx context.Context) {
+		events.SendCtx(ctx, "child")
+	}))
+	// 后续事件的订阅不能确认触发它的发送
+	assert.Equal(t, ErrNoAck, events.SendAck("parent", 50*time.Millisecond))
+	assert.False(t, <-acked)
+	assert.False(t, Ack(context.Background()))
+}
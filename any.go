@@ -0,0 +1,81 @@
+package eventbus
+
+import "sort"
+
+// OnAny 注册全量订阅，接收每一次 Send 的事件，在事件自身的订阅(或兜底订阅)之后调用
+// 调用方法的第一个参数必须是 string，接收实际发送的事件，入参个数不匹配的发送被跳过
+// 只有全量订阅能够处理的事件，Send 不返回 ErrNotFound。同一订阅器允许注册多个全量订阅，计入 WithMaxHandlers 的总数
+func (p *EventBus) OnAny(call interface{}) error {
+	if p.isFrozen() {
+		return ErrFrozen
+	}
+	e := &event{key: "any", call: call, withKey: true}
+	if err := p.setup(e); err != nil {
+		return err
+	}
+	if err := p.authorizeAny(); err != nil {
+		return err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.maxHandlers > 0 && p.handlerCount >= p.maxHandlers {
+		return ErrHandlerLimit
+	}
+	p.anys = append(p.anys[:len(p.anys):len(p.anys)], e)
+	p.handlerCount++
+	return nil
+}
+
+// OnExisting 为当前已注册的每个事件追加一个订阅，call 接收事件和全部入参
+// 只作用于调用时已经存在的事件，之后注册的事件不会调用，这一点与 OnAny 不同。通配事件不追加
+// 全部事件在同一把锁内注册，任何一个事件校验失败时都不追加
+func (p *EventBus) OnExisting(call func(eventKey string, args []interface{})) error {
+	if p.isFrozen() {
+		return ErrFrozen
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var added []*event
+	p.events.Range(func(k, _ interface{}) bool {
+		key := k.(string)
+		if isPattern(key) {
+			return true
+		}
+		raw := func(args []interface{}) error {
+			call(key, args)
+			return nil
+		}
+		added = append(added, &event{key: key, call: raw, raw: raw})
+		return true
+	})
+	sort.Slice(added, func(i, j int) bool { return added[i].key < added[j].key })
+	if p.maxHandlers > 0 && p.handlerCount+len(added) > p.maxHandlers {
+		return ErrHandlerLimit
+	}
+	for _, e := range added {
+		if err := p.authorize(e.key); err != nil {
+			return err
+		}
+		if err := p.setup(e); err != nil {
+			return err
+		}
+	}
+	now := p.clock.Now()
+	for _, e := range added {
+		e.registered = now
+		handlers := p.handlers(e.key)
+		p.put(e.key, handlers, append(handlers[:len(handlers):len(handlers)], e))
+	}
+	return nil
+}
+
+// firehose 返回能够接收 args 的全量订阅，调用方需持有 mu
+func (p *EventBus) firehose(args []interface{}) []*event {
+	var matched []*event
+	for _, e := range p.anys {
+		if e.accept(args, p.ignoreExtraArgs) {
+			matched = append(matched, e)
+		}
+	}
+	return matched
+}
@@ -0,0 +1,107 @@
+package eventbus
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventBus_OnAnyPrecedence(t *testing.T) {
+	type setup struct {
+		key, any, fallback bool
+	}
+	tests := []struct {
+		name  string
+		setup setup
+		err   error
+		calls []string
+	}{
+		{"key+firehose", setup{key: true, any: true}, nil, []string{"key", "any:evt"}},
+		{"key only", setup{key: true}, nil, []string{"key"}},
+		{"firehose only", setup{any: true}, nil, []string{"any:evt"}},
+		{"fallback only", setup{fallback: true}, nil, []string{"fallback:evt"}},
+		{"fallback+firehose", setup{any: true, fallback: true}, nil, []string{"fallback:evt", "any:evt"}},
+		{"none", setup{}, ErrNotFound, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events := New()
+			var (
+				mu    sync.Mutex
+				calls []string
+			)
+			record := func(s string) {
+				mu.Lock()
+				calls = append(calls, s)
+				mu.Unlock()
+			}
+			if tt.setup.key {
+				assert.NoError(t, events.On("evt", func(int) { record("key") }))
+			}
+			if tt.setup.any {
+				assert.NoError(t, events.OnAny(func(key string, args ...interface{}) { record("any:" + key) }))
+			}
+			if tt.setup.fallback {
+				assert.NoError(t, events.OnFallback(func(key string, args ...interface{}) { record("fallback:" + key) }))
+			}
+			assert.Equal(t, tt.err, events.Send("evt", 1))
+			events.Close()
+			assert.Equal(t, tt.calls, calls)
+		})
+	}
+}
+
+func TestEventBus_OnAnyArgsNotMatch(t *testing.T) {
+	events := New()
+	defer events.Close()
+	assert.NoError(t, events.OnAny(func(key string, n int) {}))
+	assert.EqualError(t, events.OnAny(func(n int) {}), ErrNotCallable.Error())
+	// 入参个数不匹配的全量订阅被跳过
+	assert.EqualError(t, events.Send("evt", 1, 2), ErrNotFound.Error())
+	assert.NoError(t, events.Send("evt", 1))
+}
+
+func TestEventBus_OnExisting(t *testing.T) {
+	events := New()
+	defer events.Close()
+	assert.NoError(t, events.On("order.created", func(id int) {}))
+	assert.NoError(t, events.On("user.login", func(name string) {}))
+	assert.NoError(t, events.OnWildcard("order.*", func(key string, id int) {}))
+
+	var got []string
+	assert.NoError(t, events.OnExisting(func(eventKey string, args []interface{}) {
+		got = append(got, eventKey)
+		assert.Len(t, args, 1)
+	}))
+	assert.NoError(t, events.On("user.logout", func(name string) {}))
+
+	assert.NoError(t, events.SendSync("order.created", 1))
+	assert.NoError(t, events.SendSync("user.login", "a"))
+	assert.NoError(t, events.SendSync("user.logout", "a"))
+	assert.NoError(t, events.SendSync("order.paid", 2))
+	// 之后注册的事件和通配匹配的事件不调用
+	assert.Equal(t, []string{"order.created", "user.login"}, got)
+}
+
+func TestEventBus_OnExistingAtomic(t *testing.T) {
+	events := New(WithMaxHandlers(3))
+	defer events.Close()
+	assert.NoError(t, events.On("a", func() {}))
+	assert.NoError(t, events.On("b", func() {}))
+	noop := func(string, []interface{}) {}
+	// 只能再注册一个订阅，两个事件都不追加
+	assert.Equal(t, ErrHandlerLimit, events.OnExisting(noop))
+	assert.Len(t, events.handlers("a"), 1)
+	assert.Len(t, events.handlers("b"), 1)
+
+	protected := New(WithProtectedPrefix("sys.", "github.com/app/core"))
+	defer protected.Close()
+	protected.callerPackage = func() string { return "github.com/app/core" }
+	assert.NoError(t, protected.On("app.start", func() {}))
+	assert.NoError(t, protected.On("sys.boot", func() {}))
+	protected.callerPackage = func() string { return "github.com/app/plugin" }
+	assert.Equal(t, ErrUnauthorized, protected.OnExisting(noop))
+	assert.Len(t, protected.handlers("app.start"), 1)
+	assert.Len(t, protected.handlers("sys.boot"), 1)
+}
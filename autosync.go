@@ -0,0 +1,66 @@
+package eventbus
+
+import (
+	"reflect"
+	"sync/atomic"
+)
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// WithAutoSyncForErrorReturns 按订阅的签名选择调用方式：最后一个返回值是 error 的订阅(含 OnRaw)
+// 在发送方协程中同步调用，Send 返回第一个错误；没有 error 返回值的订阅照常异步调用。
+// 同步的订阅先于异步的订阅调用，SendNotifyAll、SendAck、SendBubble 不受影响
+func WithAutoSyncForErrorReturns() Option {
+	return func(p *EventBus) {
+		p.autoSync = true
+	}
+}
+
+// returnsError 判断订阅的最后一个返回值是否是 error，OnLazy 注册的订阅无法提前判断，按异步处理
+func (e *event) returnsError() bool {
+	if e.isRaw() {
+		return true
+	}
+	if e.lazy != nil {
+		return false
+	}
+	t := reflect.TypeOf(e.call)
+	return t.NumOut() > 0 && t.Out(t.NumOut()-1) == errorType
+}
+
+// splitSend 同步调用 s 中返回 error 的订阅，其余的订阅放入队列
+// 两部分属于同一次发送，共用一个发送ID并且只计数一次，全部订阅都是同步调用时直接记录 SendTracked 的结果
+func (p *EventBus) splitSend(s *sender) error {
+	var syncs, asyncs []*event
+	for _, e := range s.events {
+		if e.returnsError() {
+			syncs = append(syncs, e)
+		} else {
+			asyncs = append(asyncs, e)
+		}
+	}
+	var err error
+	if len(syncs) > 0 {
+		state, stateErr := newSyncState(s.ctx)
+		if stateErr != nil {
+			p.release()
+			return stateErr
+		}
+		s.id = atomic.AddUint64(&p.sendSeq, 1)
+		atomic.AddInt64(&p.stats.sent, 1)
+		err = p.callSync(&sender{id: s.id, key: s.key, args: s.args, events: syncs, ctx: s.ctx, priority: s.priority, sync: state})
+	}
+	if len(asyncs) == 0 {
+		// 没有需要异步调用的订阅，归还 prepare 占用的名额
+		p.release()
+		p.startResult(s)
+		p.finishResult(s, err)
+		return err
+	}
+	s.events = asyncs
+	if enqueueErr := p.enqueue(s); enqueueErr != nil {
+		return enqueueErr
+	}
+	p.watchCancel(s)
+	return err
+}
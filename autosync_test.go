@@ -0,0 +1,88 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithAutoSyncForErrorReturns(t *testing.T) {
+	events := New(WithAutoSyncForErrorReturns())
+	release := make(chan struct{})
+	voidDone := make(chan struct{})
+	var syncRan bool
+	assert.NoError(t, events.On("order", func(id int) error {
+		syncRan = true
+		return errors.New("rejected")
+	}))
+	assert.NoError(t, events.On("order", func(id int) {
+		<-release
+		close(voidDone)
+	}))
+
+	// 返回 error 的订阅在 Send 返回前执行，错误返回给发送方
+	assert.EqualError(t, events.Send("order", 1), "rejected")
+	assert.True(t, syncRan)
+	// 没有返回值的订阅异步执行，Send 不等待
+	select {
+	case <-voidDone:
+		t.Fatal("void handler ran synchronously")
+	default:
+	}
+	close(release)
+	select {
+	case <-voidDone:
+	case <-time.After(time.Second):
+		t.Fatal("void handler not dispatched")
+	}
+	events.Close()
+	assert.Equal(t, 0.5, events.ErrorRate("order"))
+}
+
+func TestWithAutoSyncForErrorReturns_Slots(t *testing.T) {
+	events := New(WithAutoSyncForErrorReturns(), WithMaxGoroutines(1), WithGoroutineLimitFailFast())
+	assert.NoError(t, events.On("check", func() error { return nil }))
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, events.Send("check"))
+	}
+	events.Close()
+	// 只有同步调用的发送不占用名额
+	assert.Len(t, events.slots, 0)
+}
+
+func TestWithAutoSyncForErrorReturns_OneSend(t *testing.T) {
+	events := New(WithAutoSyncForErrorReturns())
+	ids := make(chan uint64, 2)
+	assert.NoError(t, events.On("mixed", func(ctx context.Context) error {
+		id, _ := Sequence(ctx)
+		ids <- id
+		return nil
+	}))
+	assert.NoError(t, events.On("mixed", func(ctx context.Context) {
+		id, _ := Sequence(ctx)
+		ids <- id
+	}))
+	failed := errors.New("failed")
+	assert.NoError(t, events.On("sync", func() error { return failed }))
+
+	// 同步和异步两部分共用一个发送ID，只计数一次
+	id, err := events.SendTracked("mixed")
+	assert.NoError(t, err)
+	assert.NotZero(t, id)
+	assert.Equal(t, id, <-ids)
+	assert.Equal(t, id, <-ids)
+
+	// 全部订阅同步调用时返回发送ID，结果已经结束
+	id, err = events.SendTracked("sync")
+	assert.Equal(t, failed, err)
+	assert.NotZero(t, id)
+	done, err := events.Result(id)
+	assert.True(t, done)
+	assert.Equal(t, failed, err)
+
+	events.Close()
+	assert.Equal(t, int64(2), events.Stats().Sent)
+}
@@ -0,0 +1,219 @@
+package eventbus
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// a batcher 累积发送的入参，达到数量或等待时间后一次性交给订阅
+type batcher struct {
+	mu      sync.Mutex
+	maxSize int
+	maxWait time.Duration
+	call    func(batch [][]interface{})
+	items   []batchItem
+	timer   *time.Timer
+	// canceled 输出时调用，传入 context 已经取消而被剔除的入参
+	canceled func(args []interface{}, err error)
+	// maxBytes 批次入参编码后的总字节数上限，size 估算一次发送的字节数
+	maxBytes int
+	size     func(args []interface{}) int
+	bytes    int
+	// gen 每输出一个批次加一，过期的定时器不再输出
+	gen uint64
+	// retire 不为空时批次输出后停用 batcher 并调用 retire，之后的 add 返回 false
+	retire  func()
+	retired bool
+}
+
+// a batchItem 批次中的一次发送，ctx 为 SendCtx 传入的 context
+type batchItem struct {
+	args []interface{}
+	ctx  context.Context
+}
+
+// OnBatch 注册批量订阅，累积 maxSize 次发送或距离批次中第一次发送 maxWait 之后，
+// 将这些发送的入参一次性交给 call，适合批量写库等场景。同一事件允许注册多个订阅
+// Close 时尚未输出的批次会立即输出，输出前 SendCtx 的 context 已经取消的发送不在批次中
+func (p *EventBus) OnBatch(eventKey string, maxSize int, maxWait time.Duration, call func(batch [][]interface{})) error {
+	return p.OnBatchWithCancel(eventKey, maxSize, maxWait, call, nil)
+}
+
+// OnBatchWithCancel 同 OnBatch，批次输出时 context 已经取消的发送被剔除并交给 canceled
+func (p *EventBus) OnBatchWithCancel(eventKey string, maxSize int, maxWait time.Duration, call func(batch [][]interface{}), canceled func(args []interface{}, err error)) error {
+	if maxSize < 1 {
+		maxSize = 1
+	}
+	b := p.newBatcher(maxSize, maxWait, call)
+	b.canceled = canceled
+	e := &event{key: eventKey, call: call, rawCtx: func(ctx context.Context, args []interface{}) error {
+		b.add(batchItem{args: args, ctx: ctx})
+		return nil
+	}}
+	if err := p.on(e, false); err != nil {
+		return err
+	}
+	p.mu.Lock()
+	p.batchers = append(p.batchers, b)
+	p.mu.Unlock()
+	return nil
+}
+
+// OnGroupedBatch 注册按组批量的订阅，groupFn 根据入参计算分组，每个分组各自累积，
+// 距离分组中第一次发送 maxWait 之后把该分组的入参一次性交给 call，适合按租户批量处理
+// Close 时尚未输出的批次会立即输出。同一事件允许注册多个订阅
+func (p *EventBus) OnGroupedBatch(eventKey string, groupFn func(args []interface{}) string, maxWait time.Duration, call func(groupKey string, batch [][]interface{})) error {
+	var (
+		mu     sync.Mutex
+		groups = map[string]*batcher{}
+	)
+	e := &event{key: eventKey, call: call, rawCtx: func(ctx context.Context, args []interface{}) error {
+		group := groupFn(args)
+		for {
+			mu.Lock()
+			b, ok := groups[group]
+			if !ok {
+				b = p.newBatcher(math.MaxInt32, maxWait, func(batch [][]interface{}) {
+					call(group, batch)
+				})
+				// 分组输出后即移除，分组过多时不会一直占用内存
+				b.retire = func() {
+					mu.Lock()
+					if groups[group] == b {
+						delete(groups, group)
+					}
+					mu.Unlock()
+					p.removeBatcher(b)
+				}
+				groups[group] = b
+			}
+			mu.Unlock()
+			if !ok {
+				p.mu.Lock()
+				p.batchers = append(p.batchers, b)
+				p.mu.Unlock()
+			}
+			// 取到的 batcher 刚好输出并停用时使用新的 batcher
+			if b.add(batchItem{args: args, ctx: ctx}) {
+				return nil
+			}
+		}
+	}}
+	return p.on(e, false)
+}
+
+// WithBatchMaxBytes 批量订阅的批次入参按 Codec 编码后超过 n 字节时立即输出，防止积压的大入参占用过多内存
+func WithBatchMaxBytes(n int) Option {
+	return func(p *EventBus) {
+		p.batchMaxBytes = n
+	}
+}
+
+// newBatcher 按订阅器的配置构建 batcher
+func (p *EventBus) newBatcher(maxSize int, maxWait time.Duration, call func(batch [][]interface{})) *batcher {
+	b := &batcher{maxSize: maxSize, maxWait: maxWait, call: call, maxBytes: p.batchMaxBytes}
+	if b.maxBytes > 0 {
+		codec := p.codec
+		b.size = func(args []interface{}) int {
+			data, err := codec.Marshal(args)
+			if err != nil {
+				return 0
+			}
+			return len(data)
+		}
+	}
+	return b
+}
+
+// removeBatcher 从 Close 需要输出的 batcher 中移除 b
+func (p *EventBus) removeBatcher(b *batcher) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	rest := make([]*batcher, 0, len(p.batchers))
+	for _, other := range p.batchers {
+		if other != b {
+			rest = append(rest, other)
+		}
+	}
+	p.batchers = rest
+}
+
+// add 放入一次发送的入参，达到 maxSize 或 maxBytes 时立即输出，batcher 已经停用时返回 false
+func (b *batcher) add(item batchItem) bool {
+	b.mu.Lock()
+	if b.retired {
+		b.mu.Unlock()
+		return false
+	}
+	b.items = append(b.items, item)
+	if b.size != nil {
+		b.bytes += b.size(item.args)
+	}
+	if len(b.items) >= b.maxSize || (b.maxBytes > 0 && b.bytes >= b.maxBytes) {
+		batch := b.take()
+		b.mu.Unlock()
+		b.output(batch)
+		return true
+	}
+	if b.timer == nil {
+		gen := b.gen
+		b.timer = time.AfterFunc(b.maxWait, func() { b.flush(gen) })
+	}
+	b.mu.Unlock()
+	return true
+}
+
+// output 把批次交给订阅，context 已经取消的发送被剔除，需要停用的 batcher 随后调用 retire
+func (b *batcher) output(items []batchItem) {
+	batch := make([][]interface{}, 0, len(items))
+	for _, item := range items {
+		if item.ctx != nil && item.ctx.Err() != nil {
+			if b.canceled != nil {
+				b.canceled(item.args, item.ctx.Err())
+			}
+			continue
+		}
+		batch = append(batch, item.args)
+	}
+	if len(batch) > 0 {
+		b.call(batch)
+	}
+	if b.retire != nil {
+		b.retire()
+	}
+}
+
+// generation 返回当前批次的编号
+func (b *batcher) generation() uint64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.gen
+}
+
+// flush 输出编号为 gen 的批次，批次已经输出时忽略
+func (b *batcher) flush(gen uint64) {
+	b.mu.Lock()
+	if gen != b.gen || len(b.items) == 0 {
+		b.mu.Unlock()
+		return
+	}
+	batch := b.take()
+	b.mu.Unlock()
+	b.output(batch)
+}
+
+// take 取出当前批次并开始新的批次，调用方需持有 mu
+func (b *batcher) take() []batchItem {
+	batch := b.items
+	b.items = nil
+	b.bytes = 0
+	b.gen++
+	b.retired = b.retire != nil
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	return batch
+}
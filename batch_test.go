@@ -0,0 +1,147 @@
+package eventbus
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventBus_OnBatchSize(t *testing.T) {
+	events := New()
+	batches := make(chan [][]interface{}, 4)
+	assert.NoError(t, events.OnBatch("row", 3, time.Hour, func(batch [][]interface{}) {
+		batches <- batch
+	}))
+	for i := 0; i < 7; i++ {
+		assert.NoError(t, events.Send("row", i))
+	}
+	assert.Len(t, <-batches, 3)
+	assert.Len(t, <-batches, 3)
+	// 剩余的一条在关闭时输出
+	events.Close()
+	assert.Len(t, <-batches, 1)
+}
+
+func TestEventBus_OnBatchWithCancel(t *testing.T) {
+	events := New()
+	batches := make(chan [][]interface{}, 1)
+	canceled := make(chan []interface{}, 1)
+	assert.NoError(t, events.OnBatchWithCancel("row", 100, time.Hour, func(batch [][]interface{}) {
+		batches <- batch
+	}, func(args []interface{}, err error) {
+		assert.Equal(t, context.Canceled, err)
+		canceled <- args
+	}))
+	ctx, cancel := context.WithCancel(context.Background())
+	assert.NoError(t, events.SendSyncCtx(context.Background(), "row", 1))
+	assert.NoError(t, events.SendSyncCtx(ctx, "row", 2))
+	assert.NoError(t, events.SendSyncCtx(context.Background(), "row", 3))
+	// 批次输出前取消，该发送被剔除
+	cancel()
+	events.Close()
+	assert.Equal(t, [][]interface{}{{1}, {3}}, <-batches)
+	assert.Equal(t, []interface{}{2}, <-canceled)
+}
+
+func TestEventBus_OnBatchWait(t *testing.T) {
+	events := New()
+	defer events.Close()
+	batches := make(chan [][]interface{}, 2)
+	assert.NoError(t, events.OnBatch("row", 100, 20*time.Millisecond, func(batch [][]interface{}) {
+		batches <- batch
+	}))
+	assert.NoError(t, events.Send("row", "a", 1))
+	assert.NoError(t, events.Send("row", "b", 2))
+
+	select {
+	case batch := <-batches:
+		assert.ElementsMatch(t, [][]interface{}{{"a", 1}, {"b", 2}}, batch)
+	case <-time.After(time.Second):
+		t.Fatal("batch not flushed after maxWait")
+	}
+}
+
+func TestEventBus_OnGroupedBatch(t *testing.T) {
+	events := New()
+	type output struct {
+		group string
+		batch [][]interface{}
+	}
+	outputs := make(chan output, 2)
+	assert.NoError(t, events.OnGroupedBatch("usage", func(args []interface{}) string {
+		return args[0].(string)
+	}, 20*time.Millisecond, func(group string, batch [][]interface{}) {
+		outputs <- output{group, batch}
+	}))
+	assert.NoError(t, events.Send("usage", "tenant-a", 1))
+	assert.NoError(t, events.Send("usage", "tenant-b", 2))
+	assert.NoError(t, events.Send("usage", "tenant-a", 3))
+
+	got := map[string][][]interface{}{}
+	for i := 0; i < 2; i++ {
+		select {
+		case o := <-outputs:
+			got[o.group] = o.batch
+		case <-time.After(time.Second):
+			t.Fatal("grouped batch not flushed")
+		}
+	}
+	events.Close()
+	assert.ElementsMatch(t, [][]interface{}{{"tenant-a", 1}, {"tenant-a", 3}}, got["tenant-a"])
+	assert.Equal(t, [][]interface{}{{"tenant-b", 2}}, got["tenant-b"])
+}
+
+func TestWithBatchMaxBytes(t *testing.T) {
+	events := New(WithBatchMaxBytes(1024))
+	batches := make(chan [][]interface{}, 4)
+	assert.NoError(t, events.OnBatch("blob", 100, time.Hour, func(batch [][]interface{}) {
+		batches <- batch
+	}))
+	large := strings.Repeat("x", 400)
+	for i := 0; i < 5; i++ {
+		assert.NoError(t, events.Send("blob", large))
+	}
+	// 每条约 400 字节，累积到第 3 条超过 1024 字节
+	select {
+	case batch := <-batches:
+		assert.Len(t, batch, 3)
+	case <-time.After(time.Second):
+		t.Fatal("batch not flushed on byte threshold")
+	}
+	events.Close()
+	assert.Len(t, <-batches, 2)
+}
+
+func TestOnGroupedBatch_Retire(t *testing.T) {
+	events := New()
+	flushed := make(chan string, 10)
+	assert.NoError(t, events.OnGroupedBatch("tenant", func(args []interface{}) string {
+		return args[0].(string)
+	}, 10*time.Millisecond, func(group string, batch [][]interface{}) {
+		flushed <- group
+	}))
+	for i := 0; i < 5; i++ {
+		assert.NoError(t, events.Send("tenant", fmt.Sprintf("t%d", i)))
+	}
+	for i := 0; i < 5; i++ {
+		select {
+		case <-flushed:
+		case <-time.After(time.Second):
+			t.Fatal("group not flushed")
+		}
+	}
+	// 输出后的分组被移除
+	assert.Eventually(t, func() bool {
+		events.mu.RLock()
+		defer events.mu.RUnlock()
+		return len(events.batchers) == 0
+	}, time.Second, time.Millisecond)
+	// 同一分组再次发送时重新创建
+	assert.NoError(t, events.Send("tenant", "t0"))
+	events.Close()
+	assert.Equal(t, "t0", <-flushed)
+}
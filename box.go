@@ -0,0 +1,74 @@
+package eventbus
+
+import "reflect"
+
+// WithArgBoxing 入参个数不匹配时在结构体和字段之间自动转换，需要在注册订阅前生效:
+// 订阅只接收一个结构体而发送方按字段顺序分别发送时，装入结构体；
+// 发送方发送一个结构体而订阅按字段顺序接收时，拆分为字段。结构体的字段必须全部导出
+func WithArgBoxing() Option {
+	return func(p *EventBus) {
+		p.argBoxing = true
+	}
+}
+
+// rebox 尝试把入参转换为订阅需要的形式
+func (e *event) rebox(args []interface{}) ([]interface{}, bool) {
+	t := reflect.TypeOf(e.call)
+	if e.argsNums == 1 && len(args) > 1 {
+		return boxArgs(paramType(t, len(e.injects)), args)
+	}
+	if len(args) == 1 {
+		return unboxArgs(args[0], e.argsNums)
+	}
+	return nil, false
+}
+
+// boxArgs 按字段顺序把入参装入 t 类型的结构体，t 可以是结构体指针
+func boxArgs(t reflect.Type, args []interface{}) ([]interface{}, bool) {
+	st := t
+	if st.Kind() == reflect.Ptr {
+		st = st.Elem()
+	}
+	if st.Kind() != reflect.Struct || st.NumField() != len(args) {
+		return nil, false
+	}
+	v := reflect.New(st).Elem()
+	for i, arg := range args {
+		field := v.Field(i)
+		if !field.CanSet() {
+			return nil, false
+		}
+		if arg == nil {
+			continue
+		}
+		av := reflect.ValueOf(arg)
+		if !av.Type().AssignableTo(field.Type()) {
+			return nil, false
+		}
+		field.Set(av)
+	}
+	if t.Kind() == reflect.Ptr {
+		return []interface{}{v.Addr().Interface()}, true
+	}
+	return []interface{}{v.Interface()}, true
+}
+
+// unboxArgs 把结构体 arg 按字段顺序拆分为 n 个入参，arg 可以是结构体指针
+func unboxArgs(arg interface{}, n int) ([]interface{}, bool) {
+	v := reflect.ValueOf(arg)
+	if v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct || v.NumField() != n {
+		return nil, false
+	}
+	args := make([]interface{}, n)
+	for i := range args {
+		field := v.Field(i)
+		if !field.CanInterface() {
+			return nil, false
+		}
+		args[i] = field.Interface()
+	}
+	return args, true
+}
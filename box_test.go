@@ -0,0 +1,71 @@
+package eventbus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type orderCreated struct {
+	ID    int
+	Price float64
+}
+
+func TestWithArgBoxing_Box(t *testing.T) {
+	events := New(WithArgBoxing())
+	defer events.Close()
+
+	got := make(chan orderCreated, 1)
+	events.On("order", func(o orderCreated) {
+		got <- o
+	})
+	events.On("orderPtr", func(o *orderCreated) {
+		got <- *o
+	})
+
+	for _, key := range []string{"order", "orderPtr"} {
+		err := events.Send(key, 1, 9.5)
+		assert.NoError(t, err)
+		select {
+		case o := <-got:
+			assert.Equal(t, orderCreated{1, 9.5}, o)
+		case <-time.After(time.Second):
+			t.Fatal("event not received")
+		}
+	}
+
+	// 字段个数不同或类型不匹配
+	err := events.Send("order", 1, 9.5, "extra")
+	assert.EqualError(t, err, ErrArgsNotMatch.Error())
+	err = events.Send("order", "1", 9.5)
+	assert.EqualError(t, err, ErrArgsNotMatch.Error())
+}
+
+func TestWithArgBoxing_Unbox(t *testing.T) {
+	events := New(WithArgBoxing())
+	defer events.Close()
+
+	got := make(chan orderCreated, 1)
+	events.On("order", func(id int, price float64) {
+		got <- orderCreated{id, price}
+	})
+
+	for _, arg := range []interface{}{orderCreated{2, 1.5}, &orderCreated{2, 1.5}} {
+		err := events.Send("order", arg)
+		assert.NoError(t, err)
+		select {
+		case o := <-got:
+			assert.Equal(t, orderCreated{2, 1.5}, o)
+		case <-time.After(time.Second):
+			t.Fatal("event not received")
+		}
+	}
+
+	// 未开启时依旧校验入参个数
+	strict := New()
+	defer strict.Close()
+	strict.On("order", func(id int, price float64) {})
+	err := strict.Send("order", orderCreated{2, 1.5})
+	assert.EqualError(t, err, ErrArgsNotMatch.Error())
+}
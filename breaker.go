@@ -0,0 +1,73 @@
+package eventbus
+
+import (
+	"sync"
+	"time"
+)
+
+// WithCircuitBreaker 按事件开启熔断，连续 failureThreshold 次执行失败后熔断器打开，
+// 之后的发送直接返回 ErrCircuitOpen，不调用订阅。经过 resetTimeout 后允许一次试探发送，
+// 试探成功时熔断器关闭，失败时重新打开。试探结果返回前的其他发送仍然返回 ErrCircuitOpen
+func WithCircuitBreaker(failureThreshold int, resetTimeout time.Duration) Option {
+	return func(p *EventBus) {
+		p.breaker = &breaker{threshold: failureThreshold, reset: resetTimeout, keys: make(map[string]*circuit)}
+	}
+}
+
+// breaker 按事件记录的熔断器
+type breaker struct {
+	mu        sync.Mutex
+	threshold int
+	reset     time.Duration
+	keys      map[string]*circuit
+}
+
+// circuit 一个事件的熔断状态，只保存有连续失败的事件
+type circuit struct {
+	failures int
+	open     bool
+	// until 熔断器打开时，在此之前的发送被拒绝
+	until time.Time
+}
+
+// allow 判断是否允许发送，熔断器打开且超过 resetTimeout 时放行一次试探发送
+func (b *breaker) allow(eventKey string, now time.Time) error {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c, ok := b.keys[eventKey]
+	if !ok || !c.open {
+		return nil
+	}
+	if now.Before(c.until) {
+		return ErrCircuitOpen
+	}
+	// 试探结果返回前拒绝其他发送，试探没有结果时再经过 resetTimeout 放行下一次
+	c.until = now.Add(b.reset)
+	return nil
+}
+
+// record 记录一次执行结果，成功时关闭熔断器
+func (b *breaker) record(eventKey string, err error, now time.Time) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err == nil {
+		delete(b.keys, eventKey)
+		return
+	}
+	c, ok := b.keys[eventKey]
+	if !ok {
+		c = &circuit{}
+		b.keys[eventKey] = c
+	}
+	c.failures++
+	if c.open || c.failures >= b.threshold {
+		c.open = true
+		c.until = now.Add(b.reset)
+	}
+}
@@ -0,0 +1,56 @@
+package eventbus
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithCircuitBreaker(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	events := New(WithClock(clock), WithCircuitBreaker(2, time.Minute))
+	defer events.Close()
+	failed := errors.New("downstream unavailable")
+	healthy := false
+	calls := 0
+	assert.NoError(t, events.On("charge", func() error {
+		calls++
+		if healthy {
+			return nil
+		}
+		return failed
+	}))
+	assert.NoError(t, events.On("other", func() error { return nil }))
+
+	assert.Equal(t, failed, events.SendSync("charge"))
+	assert.Equal(t, failed, events.SendSync("charge"))
+	// 连续失败达到阈值后不再调用订阅，其他事件不受影响
+	assert.Equal(t, ErrCircuitOpen, events.SendSync("charge"))
+	assert.Equal(t, ErrCircuitOpen, events.Send("charge"))
+	assert.Equal(t, 2, calls)
+	assert.NoError(t, events.SendSync("other"))
+
+	// 超时后的试探发送失败，熔断器重新打开
+	clock.Advance(time.Minute)
+	assert.Equal(t, failed, events.SendSync("charge"))
+	assert.Equal(t, ErrCircuitOpen, events.SendSync("charge"))
+	assert.Equal(t, 3, calls)
+
+	// 试探成功后熔断器关闭
+	healthy = true
+	clock.Advance(time.Minute)
+	assert.NoError(t, events.SendSync("charge"))
+	assert.NoError(t, events.SendSync("charge"))
+	assert.Equal(t, 5, calls)
+
+	// 成功会清零连续失败次数
+	healthy = false
+	assert.Equal(t, failed, events.SendSync("charge"))
+	healthy = true
+	assert.NoError(t, events.SendSync("charge"))
+	healthy = false
+	assert.Equal(t, failed, events.SendSync("charge"))
+	assert.Equal(t, 8, calls)
+}
@@ -0,0 +1,58 @@
+package eventbus
+
+import "strings"
+
+// Propagation 传播控制，订阅的第一个参数声明为 *Propagation 时由订阅器注入，不计入入参个数
+// 同一次发送的订阅依次执行，可以在当前订阅中决定后续订阅是否执行
+type Propagation struct {
+	stopped   bool
+	immediate bool
+}
+
+// StopPropagation 不再冒泡到上级事件，同一事件的其他订阅继续执行
+func (p *Propagation) StopPropagation() {
+	p.stopped = true
+}
+
+// StopImmediatePropagation 不再冒泡，同一事件剩余的订阅也不再执行
+func (p *Propagation) StopImmediatePropagation() {
+	p.stopped = true
+	p.immediate = true
+}
+
+// SendBubble 调用事件后依次冒泡到上级事件，事件按 "." 分级，
+// 如 order.item.created 依次调用 order.item.created、order.item、order 的订阅
+// 每一层级与 Send 一样处理版本、消费组和通配订阅，任一层级存在订阅即可，
+// 全部层级都没有订阅时使用兜底订阅，没有兜底订阅返回 ErrNotFound
+func (p *EventBus) SendBubble(eventKey string, args ...interface{}) error {
+	return p.send(&sender{key: eventKey, args: args, bubble: true})
+}
+
+// bubbleLocked 从 eventKey 开始逐级查找订阅，levels 记录每个订阅所在的层级，调用方需持有 mu
+func (p *EventBus) bubbleLocked(eventKey string, args []interface{}, pick groupPick) ([]*event, []int, error) {
+	var handlers []*event
+	var levels []int
+	for key, level := eventKey, 0; ; level++ {
+		found, err := p.levelLocked(key, 0, args, pick)
+		if err != nil && err != ErrNotFound {
+			return nil, nil, err
+		}
+		handlers = append(handlers, found...)
+		for range found {
+			levels = append(levels, level)
+		}
+		i := strings.LastIndexByte(key, '.')
+		if i < 0 {
+			break
+		}
+		key = key[:i]
+	}
+	if len(handlers) > 0 {
+		return handlers, levels, nil
+	}
+	if p.fallback == nil {
+		return nil, []int{}, ErrNotFound
+	}
+	handlers, err := p.fallbackLocked(args)
+	return handlers, []int{0}, err
+}
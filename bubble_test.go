@@ -0,0 +1,111 @@
+package eventbus
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// record 注册记录调用顺序的订阅，stop 决定如何控制传播
+func record(events *EventBus, called *[]string, key, name string, stop func(p *Propagation)) {
+	events.Subscribe(key, func(p *Propagation, id int) {
+		*called = append(*called, name)
+		if stop != nil {
+			stop(p)
+		}
+	})
+}
+
+func TestEventBus_SendBubble(t *testing.T) {
+	events := New()
+	defer events.Close()
+
+	got := make(chan int, 1)
+	events.On("order", func(id int) {
+		got <- id
+	})
+	err := events.SendBubble("order.item.created", 1)
+	assert.NoError(t, err)
+	select {
+	case id := <-got:
+		assert.Equal(t, 1, id)
+	case <-time.After(time.Second):
+		t.Fatal("event not bubbled")
+	}
+
+	err = events.SendBubble("order.item", 1, 2)
+	assert.EqualError(t, err, ErrArgsNotMatch.Error())
+	err = events.SendBubble("missing.key", 1)
+	assert.EqualError(t, err, ErrNotFound.Error())
+}
+
+func TestPropagation(t *testing.T) {
+	cases := []struct {
+		name string
+		stop func(p *Propagation)
+		want []string
+	}{
+		{"none", nil, []string{"created1", "created2", "item", "order"}},
+		{"stop", (*Propagation).StopPropagation, []string{"created1", "created2"}},
+		{"immediate", (*Propagation).StopImmediatePropagation, []string{"created1"}},
+	}
+	for _, c := range cases {
+		events := New()
+		var called []string
+		record(events, &called, "order.item.created", "created1", c.stop)
+		record(events, &called, "order.item.created", "created2", nil)
+		record(events, &called, "order.item", "item", nil)
+		record(events, &called, "order", "order", nil)
+
+		// 同步执行冒泡发送，断言调用顺序
+		s := &sender{key: "order.item.created", args: []interface{}{1}, bubble: true, sync: &syncState{}}
+		_, err := events.prepare(s)
+		assert.NoError(t, err)
+		s.Call()
+		assert.Equal(t, c.want, called, c.name)
+		events.Close()
+	}
+}
+
+func TestEventBus_SendBubbleMatch(t *testing.T) {
+	events := New()
+	var mu sync.Mutex
+	var called []string
+	record := func(name string) func(int) {
+		return func(int) {
+			mu.Lock()
+			called = append(called, name)
+			mu.Unlock()
+		}
+	}
+	assert.NoError(t, events.OnGroup("order.created", "workers", record("a")))
+	assert.NoError(t, events.OnGroup("order.created", "workers", record("b")))
+	assert.NoError(t, events.OnVersion("order.created", 2, record("v2")))
+	assert.NoError(t, events.OnWildcard("order.*", func(key string, id int) { record("wild")(id) }))
+	assert.NoError(t, events.Once("order", record("once")))
+	assert.NoError(t, events.SendBubble("order.created", 1))
+	assert.NoError(t, events.SendBubble("order.created", 2))
+	events.Close()
+	// 每次只调用消费组中的一个订阅，v2 订阅不参与默认版本的发送，once 订阅只调用一次
+	assert.ElementsMatch(t, []string{"a", "wild", "once", "b", "wild"}, called)
+	assert.Empty(t, events.handlers("order"))
+}
+
+func TestEventBus_SendBubbleDedup(t *testing.T) {
+	type order struct{ ID int }
+	events := New(WithContentDedup(time.Hour), WithValidator(func(v interface{}) error {
+		if v.(order).ID < 0 {
+			return errors.New("invalid")
+		}
+		return nil
+	}))
+	assert.NoError(t, events.On("order", func(order) {}))
+	assert.NoError(t, events.SendBubble("order.created", order{1}))
+	assert.NoError(t, events.SendBubble("order.created", order{1}))
+	assert.EqualError(t, events.SendBubble("order.created", order{-1}), "invalid")
+	events.Close()
+	assert.Equal(t, int64(1), events.Stats().Sent)
+}
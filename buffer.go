@@ -0,0 +1,65 @@
+package eventbus
+
+// BufferKey 开始缓存事件的异步发送，之后的发送暂存在订阅器中，FlushKey 时一次性发出
+// 同步发送、SendNotifyAll 和 SendAck 等需要等待结果的发送不受影响
+func (p *EventBus) BufferKey(eventKey string) {
+	p.bmu.Lock()
+	defer p.bmu.Unlock()
+	if p.buffers == nil {
+		p.buffers = make(map[string][]*sender)
+	}
+	if _, ok := p.buffers[eventKey]; !ok {
+		p.buffers[eventKey] = []*sender{}
+	}
+}
+
+// FlushKey 停止缓存事件，并按发送顺序发出缓存期间的全部发送，返回第一个发送错误
+// 刷新期间新的发送继续缓存，排在已缓存的发送之后发出。事件没有处于缓存状态时直接返回 nil
+func (p *EventBus) FlushKey(eventKey string) error {
+	var first error
+	for {
+		p.bmu.Lock()
+		buffered, ok := p.buffers[eventKey]
+		if !ok || len(buffered) == 0 {
+			delete(p.buffers, eventKey)
+			p.bmu.Unlock()
+			return first
+		}
+		p.buffers[eventKey] = []*sender{}
+		p.bmu.Unlock()
+		for _, s := range buffered {
+			if err := p.send(s); err != nil && first == nil {
+				first = err
+			}
+		}
+	}
+}
+
+// GateKey 缓存事件的异步发送直到 gate 关闭或收到信号，之后按发送顺序发出，用于等待数据库连接等依赖就绪
+// 发出时的错误通过 OnSendError 报告。订阅器先于 gate 关闭时，缓存的发送返回 ErrBusClosed
+func (p *EventBus) GateKey(eventKey string, gate <-chan struct{}) {
+	p.BufferKey(eventKey)
+	go func() {
+		select {
+		case <-gate:
+		case <-p.done:
+		}
+		p.FlushKey(eventKey)
+	}()
+}
+
+// buffer 事件处于缓存状态时暂存发送，返回 true
+func (p *EventBus) buffer(s *sender) bool {
+	if s.sync != nil || s.notify || s.ack != nil || s.buffered {
+		return false
+	}
+	p.bmu.Lock()
+	defer p.bmu.Unlock()
+	buffered, ok := p.buffers[s.key]
+	if !ok {
+		return false
+	}
+	s.buffered = true
+	p.buffers[s.key] = append(buffered, s)
+	return true
+}
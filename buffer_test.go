@@ -0,0 +1,76 @@
+package eventbus
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventBus_BufferKey(t *testing.T) {
+	events := New()
+	defer events.Close()
+	got := make(chan int, 3)
+	assert.NoError(t, events.On("render", func(n int) { got <- n }))
+
+	events.BufferKey("render")
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, events.Send("render", i))
+	}
+	time.Sleep(20 * time.Millisecond)
+	assert.Len(t, got, 0)
+
+	assert.NoError(t, events.FlushKey("render"))
+	assert.ElementsMatch(t, []int{0, 1, 2}, []int{<-got, <-got, <-got})
+
+	// 刷新之后恢复直接发送
+	assert.NoError(t, events.Send("render", 3))
+	assert.Equal(t, 3, <-got)
+	assert.NoError(t, events.FlushKey("render"))
+}
+
+func TestEventBus_GateKey(t *testing.T) {
+	events := New(WithWorkers(1))
+	var mu sync.Mutex
+	var got []int
+	assert.NoError(t, events.On("write", func(n int) {
+		mu.Lock()
+		got = append(got, n)
+		mu.Unlock()
+	}))
+	gate := make(chan struct{})
+	events.GateKey("write", gate)
+	for i := 0; i < 5; i++ {
+		assert.NoError(t, events.Send("write", i))
+	}
+	time.Sleep(20 * time.Millisecond)
+	mu.Lock()
+	assert.Empty(t, got)
+	mu.Unlock()
+
+	close(gate)
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(got) == 5
+	}, time.Second, time.Millisecond)
+	assert.NoError(t, events.Send("write", 5))
+	events.Close()
+	assert.Equal(t, []int{0, 1, 2, 3, 4, 5}, got)
+
+	// 订阅器先于 gate 关闭时，缓存的发送返回 ErrBusClosed
+	closed := New()
+	errs := make(chan error, 1)
+	closed.OnSendError(func(key string, err error) { errs <- err })
+	assert.NoError(t, closed.On("write", func(n int) {}))
+	closed.GateKey("write", make(chan struct{}))
+	assert.NoError(t, closed.Send("write", 1))
+	closed.Close()
+	select {
+	case err := <-errs:
+		assert.Equal(t, ErrBusClosed, err)
+	case <-time.After(time.Second):
+		t.Fatal("buffered send not reported")
+	}
+}
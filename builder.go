@@ -0,0 +1,61 @@
+package eventbus
+
+import "time"
+
+// Builder 链式构建订阅器，与直接向 New 传入 Option 等价，便于查找和组合常用的配置
+//
+//	bus := eventbus.NewBuilder().WithWorkers(4).WithBuffer(100).WithLogger(l).Build()
+type Builder struct {
+	opts []Option
+}
+
+// NewBuilder 返回空的 Builder
+func NewBuilder() *Builder {
+	return &Builder{}
+}
+
+// With 追加任意 Option，没有对应方法的配置通过它设置
+func (b *Builder) With(opts ...Option) *Builder {
+	b.opts = append(b.opts, opts...)
+	return b
+}
+
+// WithWorkers 同 WithWorkers
+func (b *Builder) WithWorkers(n int) *Builder {
+	return b.With(WithWorkers(n))
+}
+
+// WithBuffer 限制排队和执行中的发送总数，同 WithMaxGoroutines
+func (b *Builder) WithBuffer(n int) *Builder {
+	return b.With(WithMaxGoroutines(n))
+}
+
+// WithLogger 同 WithLogger
+func (b *Builder) WithLogger(logger Logger) *Builder {
+	return b.With(WithLogger(logger))
+}
+
+// WithClock 同 WithClock
+func (b *Builder) WithClock(clock Clock) *Builder {
+	return b.With(WithClock(clock))
+}
+
+// WithCodec 同 WithCodec
+func (b *Builder) WithCodec(codec Codec) *Builder {
+	return b.With(WithCodec(codec))
+}
+
+// WithHistory 同 WithHistory
+func (b *Builder) WithHistory(size int) *Builder {
+	return b.With(WithHistory(size))
+}
+
+// WithReplyTimeout 同 WithReplyTimeout
+func (b *Builder) WithReplyTimeout(d time.Duration) *Builder {
+	return b.With(WithReplyTimeout(d))
+}
+
+// Build 按追加的顺序应用配置并构建订阅器
+func (b *Builder) Build() *EventBus {
+	return New(b.opts...)
+}
@@ -0,0 +1,31 @@
+package eventbus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type nopLogger struct{}
+
+func (nopLogger) Printf(format string, args ...interface{}) {}
+
+func TestBuilder(t *testing.T) {
+	events := NewBuilder().
+		WithWorkers(4).
+		WithBuffer(100).
+		WithLogger(nopLogger{}).
+		With(WithStickyWorkers()).
+		Build()
+	defer events.Close()
+	assert.Equal(t, 4, events.workers)
+	assert.True(t, events.stickyWorkers)
+	assert.Len(t, events.pool.chans, 4)
+	assert.Equal(t, 100, cap(events.slots))
+	assert.Equal(t, nopLogger{}, events.logger)
+
+	done := make(chan struct{})
+	assert.NoError(t, events.On("ping", func() { close(done) }))
+	assert.NoError(t, events.Send("ping"))
+	<-done
+}
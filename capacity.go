@@ -0,0 +1,22 @@
+package eventbus
+
+// WithExpectedKeys 预计使用的事件个数，按 n 预分配每个事件首次发送时创建的内部结构(错误率统计、WithHistory 的历史)，
+// 减少大量事件首次发送时的扩容。只是性能提示，超过 n 个事件依旧可用。
+// 注册表使用 sync.Map 无法预分配，注册本身的分配不受该选项影响
+func WithExpectedKeys(n int) Option {
+	return func(p *EventBus) {
+		p.expectedKeys = n
+	}
+}
+
+// presize 按 expectedKeys 预分配内部结构，在全部 Option 执行之后调用
+func (p *EventBus) presize() {
+	n := p.expectedKeys
+	if n <= 0 {
+		return
+	}
+	p.outcomes.keys = make(map[string]*outcome, n)
+	if p.history != nil {
+		p.history.keys = make(map[string]*ring, n)
+	}
+}
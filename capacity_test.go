@@ -0,0 +1,48 @@
+package eventbus
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithExpectedKeys(t *testing.T) {
+	events := New(WithExpectedKeys(16), WithHistory(1))
+	defer events.Close()
+	for i := 0; i < 32; i++ {
+		key := "key." + strconv.Itoa(i)
+		assert.NoError(t, events.On(key, func(int) {}))
+		assert.NoError(t, events.SendSync(key, i))
+	}
+	assert.Len(t, events.Keys(), 32)
+	assert.Equal(t, 0.0, events.ErrorRate("key.31"))
+}
+
+func benchmarkFirstSend(b *testing.B, opts func(n int) []Option) {
+	const n = 2000
+	keys := make([]string, n)
+	for i := range keys {
+		keys[i] = "key." + strconv.Itoa(i)
+	}
+	call := func(int) {}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		events := New(opts(n)...)
+		for _, key := range keys {
+			_ = events.On(key, call)
+			_ = events.SendSync(key, 1)
+		}
+		events.Close()
+	}
+}
+
+// BenchmarkFirstSend 注册大量事件并各发送一次，对比 WithExpectedKeys 减少的首次发送分配
+func BenchmarkFirstSend(b *testing.B) {
+	b.Run("default", func(b *testing.B) {
+		benchmarkFirstSend(b, func(int) []Option { return []Option{WithHistory(1)} })
+	})
+	b.Run("expected", func(b *testing.B) {
+		benchmarkFirstSend(b, func(n int) []Option { return []Option{WithHistory(1), WithExpectedKeys(n)} })
+	})
+}
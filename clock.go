@@ -0,0 +1,132 @@
+package eventbus
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock 订阅器使用的时钟，测试中可以替换为 FakeClock 控制时间
+type Clock interface {
+	Now() time.Time
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer Clock.AfterFunc 返回的定时器
+type Timer interface {
+	Stop() bool
+	Reset(d time.Duration) bool
+}
+
+// realClock 使用系统时间
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) AfterFunc(d time.Duration, f func()) Timer {
+	return time.AfterFunc(d, f)
+}
+
+// WithClock 使用 clock 计算排队时间、定时发送等，默认使用系统时间
+func WithClock(clock Clock) Option {
+	return func(p *EventBus) {
+		p.clock = clock
+	}
+}
+
+// SendAfter 在 d 之后调用事件，入参在到期时校验，失败时通过 OnSendError 报告
+// 到期前可以通过 CancelScheduled 取消
+func (p *EventBus) SendAfter(d time.Duration, eventKey string, args ...interface{}) error {
+	_, err := p.schedule(eventKey, d, false, args)
+	return err
+}
+
+// FakeClock 手动推进的时钟，Advance 时依次触发到期的定时器，多个订阅器可以共享同一个 FakeClock
+type FakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+}
+
+// a fakeTimer FakeClock 的定时器
+type fakeTimer struct {
+	clock  *FakeClock
+	at     time.Time
+	f      func()
+	active bool
+}
+
+// NewFakeClock 构建一个从 start 开始的时钟
+func NewFakeClock(start time.Time) *FakeClock {
+	return &FakeClock{now: start}
+}
+
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *FakeClock) AfterFunc(d time.Duration, f func()) Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := &fakeTimer{clock: c, at: c.now.Add(d), f: f, active: true}
+	c.timers = append(c.timers, t)
+	return t
+}
+
+// Advance 把时间推进 d，按到期时间顺序在当前协程中触发定时器
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	target := c.now.Add(d)
+	for {
+		sort.SliceStable(c.timers, func(i, j int) bool { return c.timers[i].at.Before(c.timers[j].at) })
+		var due *fakeTimer
+		for _, t := range c.timers {
+			if t.active && !t.at.After(target) {
+				due = t
+				break
+			}
+		}
+		if due == nil {
+			break
+		}
+		due.active = false
+		c.now = due.at
+		c.mu.Unlock()
+		due.f()
+		c.mu.Lock()
+	}
+	c.now = target
+	active := c.timers[:0]
+	for _, t := range c.timers {
+		if t.active {
+			active = append(active, t)
+		}
+	}
+	c.timers = active
+	c.mu.Unlock()
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	active := t.active
+	t.active = false
+	return active
+}
+
+func (t *fakeTimer) Reset(d time.Duration) bool {
+	c := t.clock
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	active := t.active
+	t.at = c.now.Add(d)
+	t.active = true
+	if !active {
+		c.timers = append(c.timers, t)
+	}
+	return active
+}
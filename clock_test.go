@@ -0,0 +1,44 @@
+package eventbus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFakeClock(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	var fired []int
+	clock.AfterFunc(2*time.Second, func() { fired = append(fired, 2) })
+	clock.AfterFunc(time.Second, func() { fired = append(fired, 1) })
+	stopped := clock.AfterFunc(time.Second, func() { fired = append(fired, -1) })
+	assert.True(t, stopped.Stop())
+	assert.False(t, stopped.Stop())
+
+	clock.Advance(1500 * time.Millisecond)
+	assert.Equal(t, []int{1}, fired)
+	assert.Equal(t, time.Unix(0, 0).Add(1500*time.Millisecond), clock.Now())
+
+	reset := clock.AfterFunc(time.Second, func() { fired = append(fired, 3) })
+	clock.Advance(time.Second)
+	assert.Equal(t, []int{1, 2, 3}, fired)
+	// 触发过的定时器可以重新计时
+	assert.False(t, reset.Reset(time.Second))
+	clock.Advance(time.Second)
+	assert.Equal(t, []int{1, 2, 3, 3}, fired)
+}
+
+func TestEventBus_SendAfter(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	events := New(WithClock(clock))
+	got := make(chan int, 1)
+	assert.NoError(t, events.On("tick", func(n int) { got <- n }))
+	assert.NoError(t, events.SendAfter(time.Minute, "tick", 1))
+
+	clock.Advance(59 * time.Second)
+	assert.Len(t, events.PendingSnapshot(), 0)
+	clock.Advance(time.Second)
+	assert.Equal(t, 1, <-got)
+	events.Close()
+}
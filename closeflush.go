@@ -0,0 +1,64 @@
+package eventbus
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+// WithCloseFlush 关闭时向 w 写入一行 JSON 格式的最终状态，用于重启后排查问题
+// 包含 DebugHandler 输出的统计、Counter 计数器的值，以及调用 Close 时仍在队列中的事件
+func WithCloseFlush(w io.Writer) Option {
+	return func(p *EventBus) {
+		p.closeFlush = &closeFlush{w: w}
+	}
+}
+
+// closeSummary WithCloseFlush 写入的最终状态
+type closeSummary struct {
+	debugState
+	Counters map[string]int64 `json:"counters"`
+	Pending  []closePending   `json:"pending"`
+}
+
+// closePending 调用 Close 时仍在队列中的事件，入参可能无法编码，不写入
+type closePending struct {
+	ID       uint64 `json:"id"`
+	Key      string `json:"key"`
+	Priority int    `json:"priority"`
+}
+
+type closeFlush struct {
+	w       io.Writer
+	once    sync.Once
+	pending []closePending
+}
+
+// capture 在开始关闭时按入队顺序记录队列中剩余的事件，关闭过程中它们会被调用
+func (f *closeFlush) capture(p *EventBus) {
+	if f == nil {
+		return
+	}
+	f.pending = []closePending{}
+	for _, q := range p.PendingSnapshot() {
+		f.pending = append(f.pending, closePending{ID: q.ID, Key: q.Key, Priority: q.Priority})
+	}
+}
+
+// write 全部调用结束后写入最终状态，多次 Close 只写入一次
+func (f *closeFlush) write(p *EventBus) {
+	if f == nil {
+		return
+	}
+	f.once.Do(func() {
+		summary := closeSummary{debugState: p.debugState(), Counters: map[string]int64{}, Pending: f.pending}
+		p.sharedCounters.Range(func(name, c interface{}) bool {
+			summary.Counters[name.(string)] = atomic.LoadInt64(c.(*int64))
+			return true
+		})
+		if err := json.NewEncoder(f.w).Encode(summary); err != nil {
+			p.logger.Printf("[CLOSE FLUSH] write summary: %s", err)
+		}
+	})
+}
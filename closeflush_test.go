@@ -0,0 +1,42 @@
+package eventbus
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithCloseFlush(t *testing.T) {
+	var buf bytes.Buffer
+	events := New(WithCloseFlush(&buf))
+	failed := errors.New("failed")
+	assert.NoError(t, events.On("job", func() error { return failed }))
+	assert.NoError(t, events.On("ok", func() { atomic.AddInt64(events.Counter("done"), 1) }))
+	assert.Equal(t, failed, events.SendSync("job"))
+	assert.NoError(t, events.SendSync("ok"))
+
+	// 暂停后入队的事件在关闭时仍在队列中
+	events.Pause()
+	assert.NoError(t, events.Send("ok"))
+	assert.NoError(t, events.Send("job"))
+	events.Close()
+	events.Close()
+
+	var summary map[string]interface{}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &summary))
+	assert.Equal(t, true, summary["closed"])
+	assert.Equal(t, map[string]interface{}{"done": float64(2)}, summary["counters"])
+	if pending, ok := summary["pending"].([]interface{}); assert.True(t, ok) && assert.Len(t, pending, 2) {
+		assert.Equal(t, "ok", pending[0].(map[string]interface{})["key"])
+		assert.Equal(t, "job", pending[1].(map[string]interface{})["key"])
+	}
+	if evs, ok := summary["events"].([]interface{}); assert.True(t, ok) && assert.Len(t, evs, 2) {
+		assert.Equal(t, map[string]interface{}{"key": "job", "handlers": float64(1), "call_times": float64(2), "error_rate": float64(1), "last_error": "failed"}, evs[0])
+	}
+	// 多次关闭只写入一次
+	assert.Equal(t, 1, bytes.Count(buf.Bytes(), []byte("\n")))
+}
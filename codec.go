@@ -0,0 +1,90 @@
+package eventbus
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+// Codec 跨进程转发事件时入参的编解码方式，默认使用 JSON
+type Codec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// jsonCodec 默认的 JSON 编解码
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+// WithCodec 使用 codec 编解码转发的事件，可以替换为 protobuf/msgpack/gob 等
+func WithCodec(codec Codec) Option {
+	return func(p *EventBus) {
+		p.codec = codec
+	}
+}
+
+// envelope 转发事件的编码格式，入参逐个编码，解码时按订阅的参数类型还原
+type envelope struct {
+	Key  string
+	Args [][]byte
+}
+
+// Encode 使用 Codec 编码一次发送，在另一个进程中通过 SendEncoded 调用
+func (p *EventBus) Encode(eventKey string, args ...interface{}) ([]byte, error) {
+	env := envelope{Key: eventKey, Args: make([][]byte, len(args))}
+	for i, arg := range args {
+		data, err := p.codec.Marshal(arg)
+		if err != nil {
+			return nil, err
+		}
+		env.Args[i] = data
+	}
+	return p.codec.Marshal(env)
+}
+
+// SendEncoded 解码 Encode 编码的发送并调用事件，入参按第一个订阅的参数类型解码
+// 直接接收 []interface{} 等无法确定类型的参数解码为 interface{}，没有订阅时同样解码为 interface{}，
+// 与 Send 一样交给兜底订阅、OnAny 处理，或者返回 ErrNotFound
+func (p *EventBus) SendEncoded(data []byte) error {
+	var env envelope
+	if err := p.codec.Unmarshal(data, &env); err != nil {
+		return err
+	}
+	types := p.argTypes(env.Key, len(env.Args))
+	args := make([]interface{}, len(env.Args))
+	for i, raw := range env.Args {
+		v := reflect.New(types[i])
+		if err := p.codec.Unmarshal(raw, v.Interface()); err != nil {
+			return err
+		}
+		args[i] = v.Elem().Interface()
+	}
+	return p.Send(env.Key, args...)
+}
+
+// argTypes 返回事件第一个订阅前 n 个入参的类型，没有订阅时全部为 interface{}
+func (p *EventBus) argTypes(eventKey string, n int) []reflect.Type {
+	p.mu.RLock()
+	handlers := versioned(p.handlers(eventKey), 0)
+	p.mu.RUnlock()
+	types := make([]reflect.Type, n)
+	for i := range types {
+		if len(handlers) == 0 {
+			types[i] = reflect.TypeOf((*interface{})(nil)).Elem()
+			continue
+		}
+		e := handlers[0]
+		if e.isRaw() || (!e.variadic && i >= e.argsNums) {
+			types[i] = reflect.TypeOf((*interface{})(nil)).Elem()
+			continue
+		}
+		types[i] = paramType(reflect.TypeOf(e.call), len(e.injects)+i)
+	}
+	return types
+}
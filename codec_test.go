@@ -0,0 +1,84 @@
+package eventbus
+
+import (
+	"bytes"
+	"encoding/gob"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type gobCodec struct{}
+
+func (gobCodec) Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(v)
+	return buf.Bytes(), err
+}
+
+func (gobCodec) Unmarshal(data []byte, v interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+type order struct {
+	ID    int
+	Items []string
+}
+
+func TestEventBus_SendEncoded(t *testing.T) {
+	for name, codec := range map[string]Codec{"json": nil, "gob": gobCodec{}} {
+		t.Run(name, func(t *testing.T) {
+			var opts []Option
+			if codec != nil {
+				opts = append(opts, WithCodec(codec))
+			}
+			producer := New(opts...)
+			defer producer.Close()
+			consumer := New(opts...)
+
+			got := make(chan order, 1)
+			assert.NoError(t, consumer.On("order", func(o order, n int) {
+				assert.Equal(t, 3, n)
+				got <- o
+			}))
+			data, err := producer.Encode("order", order{ID: 1, Items: []string{"a", "b"}}, 3)
+			assert.NoError(t, err)
+			assert.NoError(t, consumer.SendEncoded(data))
+			consumer.Close()
+			assert.Equal(t, order{ID: 1, Items: []string{"a", "b"}}, <-got)
+		})
+	}
+}
+
+func TestEventBus_SendEncodedNotFound(t *testing.T) {
+	events := New()
+	defer events.Close()
+	data, err := events.Encode("missing", 1)
+	assert.NoError(t, err)
+	assert.EqualError(t, events.SendEncoded(data), ErrNotFound.Error())
+}
+
+func TestEventBus_SendEncodedUnknown(t *testing.T) {
+	events := New()
+	defer events.Close()
+	var failed []string
+	events.OnSendError(func(eventKey string, err error) {
+		failed = append(failed, eventKey+": "+err.Error())
+	})
+	data, err := events.Encode("missing", "x")
+	assert.NoError(t, err)
+	assert.Equal(t, ErrNotFound, events.SendEncoded(data))
+	assert.Equal(t, []string{"missing: " + ErrNotFound.Error()}, failed)
+
+	// 没有订阅时交给兜底订阅
+	got := make(chan []interface{}, 1)
+	assert.NoError(t, events.OnFallback(func(eventKey string, args ...interface{}) {
+		got <- append([]interface{}{eventKey}, args...)
+	}))
+	assert.NoError(t, events.SendEncoded(data))
+	assert.Equal(t, []interface{}{"missing", "x"}, <-got)
+
+	strict := New(WithStrictUnknown())
+	defer strict.Close()
+	assert.Panics(t, func() { _ = strict.SendEncoded(data) })
+}
@@ -0,0 +1,32 @@
+package eventbus
+
+// OnIf 注册按条件调用的订阅，每次发送时 cond 返回 true 才调用，用于功能开关、运行环境判断等
+// 订阅一直保持注册，同一事件允许注册多个订阅。全部订阅的条件都不满足时发送直接返回 nil
+func (p *EventBus) OnIf(eventKey string, cond func() bool, call interface{}) error {
+	return p.on(&event{key: eventKey, call: call, cond: cond}, false)
+}
+
+// enabled 判断本次发送是否调用 e，OnIf 的条件不满足或不是 OnShard 对应的分片时返回 false
+func (e *event) enabled(args []interface{}) bool {
+	if e.cond != nil && !e.cond() {
+		return false
+	}
+	return e.shards == 0 || e.owns(args)
+}
+
+// enabled 剔除本次发送不调用的订阅，没有需要剔除的订阅时返回 handlers 本身
+func enabled(handlers []*event, args []interface{}) []*event {
+	kept := handlers
+	for i, e := range handlers {
+		if e.enabled(args) {
+			if len(kept) < len(handlers) {
+				kept = append(kept, e)
+			}
+			continue
+		}
+		if len(kept) == len(handlers) {
+			kept = append(make([]*event, 0, len(handlers)), handlers[:i]...)
+		}
+	}
+	return kept
+}
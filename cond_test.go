@@ -0,0 +1,29 @@
+package eventbus
+
+import (
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventBus_OnIf(t *testing.T) {
+	events := New()
+	defer events.Close()
+	var flag, flagged, always int32
+	assert.NoError(t, events.OnIf("login", func() bool { return atomic.LoadInt32(&flag) == 1 }, func() {
+		atomic.AddInt32(&flagged, 1)
+	}))
+	assert.NoError(t, events.SendSync("login"))
+	assert.Equal(t, int32(0), flagged)
+
+	atomic.StoreInt32(&flag, 1)
+	assert.NoError(t, events.SendSync("login"))
+	assert.Equal(t, int32(1), flagged)
+
+	assert.NoError(t, events.On("login", func() { atomic.AddInt32(&always, 1) }))
+	atomic.StoreInt32(&flag, 0)
+	assert.NoError(t, events.SendSync("login"))
+	assert.Equal(t, int32(1), flagged)
+	assert.Equal(t, int32(1), always)
+}
@@ -0,0 +1,69 @@
+package eventbus
+
+import (
+	"context"
+	"time"
+)
+
+// Cause 因果链中的一次发送
+type Cause struct {
+	ID       uint64
+	Key      string
+	Priority int
+}
+
+type causationKey struct{}
+
+// SendCtx 携带 context 调用事件，订阅的第一个参数声明为 context.Context 时接收由 ctx 派生的 context
+// 在订阅中使用收到的 ctx 继续发送，新事件会记录触发它的事件，形成可以通过 CausationChain 读取的因果链
+// 事件还在队列中时 ctx 被取消，事件从队列中移除，不会再被调用
+func (p *EventBus) SendCtx(ctx context.Context, eventKey string, args ...interface{}) error {
+	ctx = detach(ctx)
+	s := &sender{key: eventKey, args: args, ctx: ctx}
+	p.inherit(s)
+	if ctx.Done() != nil {
+		s.dequeued = make(chan struct{})
+	}
+	return p.send(s)
+}
+
+// SendBefore 同 SendCtx，订阅接收的 context 在 deadline 到期，订阅可以据此在超时后提前结束
+// 到期时仍在队列中的发送不再调用，不接收 context 的订阅不受影响
+func (p *EventBus) SendBefore(deadline time.Time, eventKey string, args ...interface{}) error {
+	ctx, cancel := context.WithDeadline(context.Background(), deadline)
+	s := &sender{key: eventKey, args: args, ctx: ctx, cancel: cancel, dequeued: make(chan struct{})}
+	return p.send(s)
+}
+
+// watchCancel 在 ctx 取消时把尚未出队的 s 从队列中移除
+func (p *EventBus) watchCancel(s *sender) {
+	if s.dequeued == nil {
+		return
+	}
+	go func() {
+		select {
+		case <-s.ctx.Done():
+			if p.queue.remove(s) {
+				p.finishResult(s, s.ctx.Err())
+				p.ackSend(s)
+				p.release()
+			}
+		case <-s.dequeued:
+		}
+	}()
+}
+
+// CausationChain 返回 ctx 所属发送的因果链，从最初触发的事件开始，最后一个是当前事件
+func CausationChain(ctx context.Context) []Cause {
+	chain, _ := ctx.Value(causationKey{}).([]Cause)
+	return chain
+}
+
+// CorrelationID 返回 ctx 所属事件链的关联ID，即最初触发的发送ID
+func CorrelationID(ctx context.Context) (uint64, bool) {
+	chain := CausationChain(ctx)
+	if len(chain) == 0 {
+		return 0, false
+	}
+	return chain[0].ID, true
+}
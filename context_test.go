@@ -0,0 +1,124 @@
+package eventbus
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCausationChain(t *testing.T) {
+	events := New()
+	defer events.Close()
+
+	got := make(chan context.Context, 1)
+	events.On("A", func(ctx context.Context, id int) {
+		events.SendCtx(ctx, "B")
+	})
+	events.On("B", func(ctx context.Context) {
+		got <- ctx
+	})
+
+	// context 参数不计入入参个数
+	err := events.Send("A", 1)
+	assert.NoError(t, err)
+	var ctx context.Context
+	select {
+	case ctx = <-got:
+	case <-time.After(time.Second):
+		t.Fatal("event B not received")
+	}
+
+	chain := CausationChain(ctx)
+	assert.Len(t, chain, 2)
+	assert.Equal(t, "A", chain[0].Key)
+	assert.Equal(t, "B", chain[1].Key)
+	assert.True(t, chain[0].ID < chain[1].ID)
+	id, ok := CorrelationID(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, chain[0].ID, id)
+
+	_, ok = CorrelationID(context.Background())
+	assert.False(t, ok)
+}
+
+func TestEventBus_SendCtx(t *testing.T) {
+	events := New()
+	defer events.Close()
+
+	type ctxKey struct{}
+	got := make(chan interface{}, 1)
+	events.On("value", func(ctx context.Context) {
+		got <- ctx.Value(ctxKey{})
+	})
+	err := events.SendCtx(context.WithValue(context.Background(), ctxKey{}, "v"), "value")
+	assert.NoError(t, err)
+	select {
+	case v := <-got:
+		assert.Equal(t, "v", v)
+	case <-time.After(time.Second):
+		t.Fatal("event not received")
+	}
+}
+
+func TestEventBus_SendCtxCancel(t *testing.T) {
+	events := New()
+	defer events.Close()
+
+	got := make(chan string, 2)
+	events.On("job", func(name string) {
+		got <- name
+	})
+
+	events.Pause()
+	ctx, cancel := context.WithCancel(context.Background())
+	err := events.SendCtx(ctx, "job", "canceled")
+	assert.NoError(t, err)
+	err = events.SendCtx(context.Background(), "job", "kept")
+	assert.NoError(t, err)
+	assert.Len(t, events.PendingSnapshot(), 2)
+
+	// 取消后从队列中移除
+	cancel()
+	assert.Eventually(t, func() bool {
+		return len(events.PendingSnapshot()) == 1
+	}, time.Second, time.Millisecond)
+
+	events.Resume()
+	select {
+	case name := <-got:
+		assert.Equal(t, "kept", name)
+	case <-time.After(time.Second):
+		t.Fatal("event not received")
+	}
+	select {
+	case name := <-got:
+		t.Fatalf("canceled event %s dispatched", name)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+func TestEventBus_SendBefore(t *testing.T) {
+	events := New()
+	deadline := time.Now().Add(50 * time.Millisecond)
+	got := make(chan error, 1)
+	var plain int32
+	assert.NoError(t, events.On("job", func(ctx context.Context) {
+		d, ok := ctx.Deadline()
+		assert.True(t, ok)
+		assert.Equal(t, deadline, d)
+		select {
+		case <-ctx.Done():
+			got <- ctx.Err()
+		case <-time.After(time.Second):
+			got <- nil
+		}
+	}))
+	assert.NoError(t, events.On("job", func() { atomic.AddInt32(&plain, 1) }))
+	assert.NoError(t, events.SendBefore(deadline, "job"))
+	events.Close()
+	assert.Equal(t, context.DeadlineExceeded, <-got)
+	assert.Equal(t, int32(1), plain)
+}
@@ -0,0 +1,11 @@
+package eventbus
+
+// Counter 返回订阅器范围内名为 name 的计数器，同一名称总是返回同一个计数器
+// 不同事件的订阅可以通过它共享计数，读写需要使用 sync/atomic
+func (p *EventBus) Counter(name string) *int64 {
+	if c, ok := p.sharedCounters.Load(name); ok {
+		return c.(*int64)
+	}
+	c, _ := p.sharedCounters.LoadOrStore(name, new(int64))
+	return c.(*int64)
+}
@@ -0,0 +1,22 @@
+package eventbus
+
+import (
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventBus_Counter(t *testing.T) {
+	events := New()
+	defer events.Close()
+	assert.NoError(t, events.On("order.created", func() { atomic.AddInt64(events.Counter("orders"), 1) }))
+	assert.NoError(t, events.On("order.imported", func(n int) { atomic.AddInt64(events.Counter("orders"), int64(n)) }))
+	for i := 0; i < 10; i++ {
+		assert.NoError(t, events.Send("order.created"))
+		assert.NoError(t, events.Send("order.imported", 2))
+	}
+	events.Close()
+	assert.Equal(t, int64(30), atomic.LoadInt64(events.Counter("orders")))
+	assert.Equal(t, int64(0), atomic.LoadInt64(events.Counter("other")))
+}
@@ -0,0 +1,70 @@
+package eventbus
+
+import (
+	"context"
+	"time"
+)
+
+// DeadLetter 一次调用失败的异步发送
+type DeadLetter struct {
+	// ID 失败时的发送ID
+	ID       uint64
+	Key      string
+	Args     []interface{}
+	Err      error
+	FailedAt time.Time
+}
+
+// DeadLetterStore 保存调用失败的发送，持久化的实现可以在重启后继续回放
+type DeadLetterStore interface {
+	Put(letter DeadLetter) error
+	// List 按失败的先后顺序返回全部发送
+	List() ([]DeadLetter, error)
+	Delete(id uint64) error
+}
+
+// WithDeadLetterStore 异步发送的任一订阅返回错误或 panic 时，把该发送保存到 store，之后通过 ReplayDeadLetters 重新发送
+func WithDeadLetterStore(store DeadLetterStore) Option {
+	return func(p *EventBus) {
+		p.deadLetters = store
+	}
+}
+
+// deadLetter 保存调用失败的 s
+func (p *EventBus) deadLetter(s *sender, err error) {
+	if p.deadLetters == nil {
+		return
+	}
+	letter := DeadLetter{ID: s.id, Key: s.key, Args: s.args, Err: err, FailedAt: p.clock.Now()}
+	if putErr := p.deadLetters.Put(letter); putErr != nil {
+		p.logger.Printf("[DEAD LETTER] put %s: %s", s.key, putErr)
+	}
+}
+
+// ReplayDeadLetters 按失败的顺序同步重新发送 WithDeadLetterStore 保存的发送，成功的发送从 store 中删除
+// 仍然失败的发送保留在 store 中，返回成功的个数和第一个错误。ctx 取消后停止回放
+func (p *EventBus) ReplayDeadLetters(ctx context.Context) (replayed int, err error) {
+	if p.deadLetters == nil {
+		return 0, nil
+	}
+	letters, err := p.deadLetters.List()
+	if err != nil {
+		return 0, err
+	}
+	for _, letter := range letters {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return replayed, ctxErr
+		}
+		if sendErr := p.SendSyncCtx(ctx, letter.Key, letter.Args...); sendErr != nil {
+			if err == nil {
+				err = sendErr
+			}
+			continue
+		}
+		if delErr := p.deadLetters.Delete(letter.ID); delErr != nil && err == nil {
+			err = delErr
+		}
+		replayed++
+	}
+	return replayed, err
+}
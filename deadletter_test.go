@@ -0,0 +1,81 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type memoryDeadLetters struct {
+	mu      sync.Mutex
+	letters []DeadLetter
+}
+
+func (m *memoryDeadLetters) Put(letter DeadLetter) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.letters = append(m.letters, letter)
+	return nil
+}
+
+func (m *memoryDeadLetters) List() ([]DeadLetter, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]DeadLetter(nil), m.letters...), nil
+}
+
+func (m *memoryDeadLetters) Delete(id uint64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, letter := range m.letters {
+		if letter.ID == id {
+			m.letters = append(m.letters[:i], m.letters[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func TestEventBus_ReplayDeadLetters(t *testing.T) {
+	store := &memoryDeadLetters{}
+	events := New(WithDeadLetterStore(store))
+	defer events.Close()
+	var fixed int32
+	got := make(chan int, 4)
+	assert.NoError(t, events.On("charge", func(n int) error {
+		if atomic.LoadInt32(&fixed) == 0 || n == 3 {
+			return errors.New("gateway down")
+		}
+		got <- n
+		return nil
+	}))
+	for i := 1; i <= 3; i++ {
+		assert.NoError(t, events.Send("charge", i))
+	}
+	assert.Eventually(t, func() bool {
+		letters, _ := store.List()
+		return len(letters) == 3
+	}, time.Second, time.Millisecond)
+
+	// 修复订阅后回放，仍然失败的发送保留在 store 中
+	atomic.StoreInt32(&fixed, 1)
+	replayed, err := events.ReplayDeadLetters(context.Background())
+	assert.Equal(t, 2, replayed)
+	assert.EqualError(t, err, "gateway down")
+	assert.ElementsMatch(t, []int{1, 2}, []int{<-got, <-got})
+	letters, _ := store.List()
+	if assert.Len(t, letters, 1) {
+		assert.Equal(t, []interface{}{3}, letters[0].Args)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	replayed, err = events.ReplayDeadLetters(ctx)
+	assert.Equal(t, 0, replayed)
+	assert.Equal(t, context.Canceled, err)
+}
@@ -0,0 +1,67 @@
+package eventbus
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// debugState DebugHandler 输出的状态
+type debugState struct {
+	Closed     bool         `json:"closed"`
+	Sent       int64        `json:"sent"`
+	Failed     int64        `json:"failed"`
+	QueueDepth int          `json:"queue_depth"`
+	InFlight   int64        `json:"in_flight"`
+	Events     []debugEvent `json:"events"`
+}
+
+type debugEvent struct {
+	Key       string  `json:"key"`
+	Handlers  int     `json:"handlers"`
+	CallTimes int32   `json:"call_times"`
+	ErrorRate float64 `json:"error_rate"`
+	LastError string  `json:"last_error,omitempty"`
+}
+
+// DebugHandler 返回以 JSON 输出订阅器状态的只读 http.Handler，可以挂载到 /debug/eventbus
+// 包含全部事件的订阅数、调用次数、出错比例和最近一次错误，以及队列深度等统计
+func (p *EventBus) DebugHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(p.debugState())
+	})
+}
+
+// debugState 生成订阅器的状态快照
+func (p *EventBus) debugState() debugState {
+	p.mu.RLock()
+	infos := p.snapshotLocked()
+	stats := p.Stats()
+	p.mu.RUnlock()
+	state := debugState{
+		Closed:     p.isClosed() || p.isDone(),
+		Sent:       stats.Sent,
+		Failed:     stats.Failed,
+		QueueDepth: stats.QueueDepth,
+		InFlight:   stats.InFlight,
+		Events:     []debugEvent{},
+	}
+	for _, info := range infos {
+		if n := len(state.Events); n > 0 && state.Events[n-1].Key == info.Key {
+			state.Events[n-1].Handlers++
+			state.Events[n-1].CallTimes += info.CallTimes
+			continue
+		}
+		e := debugEvent{Key: info.Key, Handlers: 1, CallTimes: info.CallTimes, ErrorRate: p.ErrorRate(info.Key)}
+		if err := p.LastError(info.Key); err != nil {
+			e.LastError = err.Error()
+		}
+		state.Events = append(state.Events, e)
+	}
+	return state
+}
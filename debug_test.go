@@ -0,0 +1,42 @@
+package eventbus
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventBus_DebugHandler(t *testing.T) {
+	events := New()
+	assert.NoError(t, events.On("ok", func() {}))
+	_, err := events.Subscribe("ok", func() {})
+	assert.NoError(t, err)
+	assert.NoError(t, events.On("boom", func() { panic("boom") }))
+	assert.NoError(t, events.Send("ok"))
+	assert.NoError(t, events.Send("boom"))
+	events.Close()
+
+	rec := httptest.NewRecorder()
+	events.DebugHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/eventbus", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	var state map[string]interface{}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &state))
+	assert.Equal(t, true, state["closed"])
+	assert.Equal(t, float64(2), state["sent"])
+	assert.Equal(t, float64(1), state["failed"])
+	assert.Contains(t, state, "queue_depth")
+	assert.Contains(t, state, "in_flight")
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"key": "boom", "handlers": float64(1), "call_times": float64(1), "error_rate": float64(1), "last_error": ErrRuntimePanic.Error() + ": boom"},
+		map[string]interface{}{"key": "ok", "handlers": float64(2), "call_times": float64(2), "error_rate": float64(0)},
+	}, state["events"])
+
+	rec = httptest.NewRecorder()
+	events.DebugHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/debug/eventbus", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
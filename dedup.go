@@ -0,0 +1,60 @@
+package eventbus
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"time"
+)
+
+// a dedup 记录窗口内发送过的内容摘要
+type dedup struct {
+	mu     sync.Mutex
+	window time.Duration
+	now    func() time.Time
+	seen   map[uint64]time.Time
+	pruned time.Time
+}
+
+// WithContentDedup 在 window 时间内事件和入参完全相同的发送只调用一次，之后的重复发送直接返回 nil
+// 用于防止发送方重试造成的重复处理。入参使用 %#v 编码后计算摘要，指针按地址比较
+func WithContentDedup(window time.Duration) Option {
+	return func(p *EventBus) {
+		p.dedup = &dedup{window: window, seen: make(map[uint64]time.Time)}
+	}
+}
+
+// claim 登记一次发送，窗口内已经发送过相同内容时返回 false
+func (d *dedup) claim(eventKey string, args []interface{}) (uint64, bool) {
+	hash := contentHash(eventKey, args)
+	now := d.now()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if now.Sub(d.pruned) >= d.window {
+		for h, at := range d.seen {
+			if now.Sub(at) >= d.window {
+				delete(d.seen, h)
+			}
+		}
+		d.pruned = now
+	}
+	if at, ok := d.seen[hash]; ok && now.Sub(at) < d.window {
+		return hash, false
+	}
+	d.seen[hash] = now
+	return hash, true
+}
+
+// release 发送失败时撤销登记，重试不会被合并
+func (d *dedup) release(hash uint64) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.seen, hash)
+}
+
+// contentHash 计算事件和入参的摘要
+func contentHash(eventKey string, args []interface{}) uint64 {
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%s\x00%#v", eventKey, args)
+	return h.Sum64()
+}
@@ -0,0 +1,43 @@
+package eventbus
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithContentDedup(t *testing.T) {
+	events := New(WithContentDedup(time.Minute))
+	now := time.Unix(0, 0)
+	events.dedup.now = func() time.Time { return now }
+	var (
+		mu       sync.Mutex
+		received []interface{}
+	)
+	assert.NoError(t, events.On("pay", func(id string, amount int) {
+		mu.Lock()
+		received = append(received, []interface{}{id, amount})
+		mu.Unlock()
+	}))
+
+	assert.NoError(t, events.Send("pay", "a", 1))
+	assert.NoError(t, events.Send("pay", "a", 1))
+	assert.NoError(t, events.Send("pay", "a", 2))
+	assert.NoError(t, events.Send("pay", "b", 1))
+	// 失败的发送不登记
+	assert.Error(t, events.Send("pay", "c"))
+	assert.Error(t, events.Send("pay", "c"))
+	// 窗口过后重新发送
+	now = now.Add(time.Minute)
+	assert.NoError(t, events.Send("pay", "a", 1))
+	events.Close()
+
+	assert.ElementsMatch(t, []interface{}{
+		[]interface{}{"a", 1},
+		[]interface{}{"a", 2},
+		[]interface{}{"b", 1},
+		[]interface{}{"a", 1},
+	}, received)
+}
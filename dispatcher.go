@@ -0,0 +1,94 @@
+package eventbus
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Dispatcher 执行出队的发送，WithDispatcher 使用它代替默认的协程或 WithWorkers 的工作协程
+// 每个 Job 必须且只能调用一次 Run 或 Discard，否则 Close 会一直等待
+type Dispatcher interface {
+	Dispatch(job *Job)
+}
+
+// Job 一次出队的发送
+type Job struct {
+	ID         uint64
+	Key        string
+	Args       []interface{}
+	Priority   int
+	EnqueuedAt time.Time
+	// DispatchedAt 出队的时间，使用订阅器的 Clock
+	DispatchedAt time.Time
+
+	bus *EventBus
+	s   *sender
+	// handled Run 或 Discard 已经调用，之后的调用被忽略
+	handled int32
+}
+
+// Run 调用发送的全部订阅并更新统计
+func (j *Job) Run() {
+	if atomic.CompareAndSwapInt32(&j.handled, 0, 1) {
+		j.bus.execute(j.s)
+	}
+}
+
+// Discard 放弃发送，不调用订阅，只释放发送占用的资源
+func (j *Job) Discard() {
+	if atomic.CompareAndSwapInt32(&j.handled, 0, 1) {
+		j.bus.discard(j.s)
+	}
+}
+
+// WithDispatcher 使用 d 执行出队的发送，设置后 WithWorkers 不再生效
+func WithDispatcher(d Dispatcher) Option {
+	return func(p *EventBus) {
+		p.dispatcher = d
+	}
+}
+
+// discard 同 execute，不调用订阅
+func (p *EventBus) discard(s *sender) {
+	defer p.running.Done()
+	defer p.release()
+	defer atomic.AddInt64(&p.stats.inFlight, -1)
+	defer p.watchdog.untrack(s)
+	p.finishResult(s, ErrDiscarded)
+	if s.results != nil {
+		close(s.results)
+	}
+}
+
+// RecordingDispatcher 按出队顺序记录发送而不调用订阅，用于在测试中确定性地断言调度顺序
+type RecordingDispatcher struct {
+	mu   sync.Mutex
+	jobs []Job
+}
+
+// Dispatch 记录并放弃 job
+func (r *RecordingDispatcher) Dispatch(job *Job) {
+	r.mu.Lock()
+	r.jobs = append(r.jobs, *job)
+	r.mu.Unlock()
+	job.Discard()
+}
+
+// Jobs 按出队顺序返回记录的发送
+func (r *RecordingDispatcher) Jobs() []Job {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Job(nil), r.jobs...)
+}
+
+// Keys 按出队顺序返回记录的事件
+func (r *RecordingDispatcher) Keys() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	keys := make([]string, len(r.jobs))
+	for i, job := range r.jobs {
+		keys[i] = job.Key
+	}
+	return keys
+}
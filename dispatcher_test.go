@@ -0,0 +1,120 @@
+package eventbus
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordingDispatcher_Priority(t *testing.T) {
+	recorder := &RecordingDispatcher{}
+	called := make(chan struct{}, 3)
+	events := New(WithDispatcher(recorder))
+	for _, key := range []string{"low", "mid", "high"} {
+		assert.NoError(t, events.On(key, func() { called <- struct{}{} }))
+	}
+	events.Pause()
+	assert.NoError(t, events.SendPriority("low", 0))
+	assert.NoError(t, events.SendPriority("mid", 5))
+	assert.NoError(t, events.SendPriority("high", 10))
+	events.Resume()
+	events.Close()
+	assert.Equal(t, []string{"high", "mid", "low"}, recorder.Keys())
+	// 记录的发送不会调用订阅
+	assert.Len(t, called, 0)
+}
+
+func TestRecordingDispatcher_FIFO(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	recorder := &RecordingDispatcher{}
+	events := New(WithDispatcher(recorder), WithClock(clock))
+	assert.NoError(t, events.On("job", func(n int) {}))
+	events.Pause()
+	for i := 0; i < 5; i++ {
+		assert.NoError(t, events.Send("job", i))
+	}
+	clock.Advance(time.Second)
+	events.Resume()
+	events.Close()
+	jobs := recorder.Jobs()
+	if assert.Len(t, jobs, 5) {
+		for i, job := range jobs {
+			assert.Equal(t, []interface{}{i}, job.Args)
+			assert.Equal(t, time.Second, job.DispatchedAt.Sub(job.EnqueuedAt))
+		}
+	}
+}
+
+type dispatcherFunc func(job *Job)
+
+func (f dispatcherFunc) Dispatch(job *Job) { f(job) }
+
+func TestEventBus_LoopRecover(t *testing.T) {
+	logger := &recordLogger{}
+	events := New(WithLogger(logger), WithDispatcher(dispatcherFunc(func(job *Job) {
+		if job.Key == "boom" {
+			panic("dispatcher bug")
+		}
+		job.Run()
+	})))
+	got := make(chan string, 2)
+	assert.NoError(t, events.On("boom", func() { got <- "boom" }))
+	assert.NoError(t, events.On("ok", func() { got <- "ok" }))
+
+	assert.NoError(t, events.Send("boom"))
+	assert.Eventually(t, func() bool { return len(logger.Lines()) == 1 }, time.Second, time.Millisecond)
+	assert.Equal(t, []string{"[LOOP PANIC RECOVER] dispatcher bug"}, logger.Lines())
+
+	// 恢复之后继续调度，panic 的发送被放弃，Close 不会一直等待
+	assert.NoError(t, events.Send("ok"))
+	assert.Equal(t, "ok", <-got)
+	events.Close()
+	assert.Len(t, got, 0)
+	assert.Equal(t, int64(0), events.Stats().InFlight)
+}
+
+// panicClock 在 arm 指定的第 n 次 Now 调用时 panic，用于模拟调度逻辑中的错误
+type panicClock struct {
+	Clock
+	countdown int32
+}
+
+func (c *panicClock) Now() time.Time {
+	if atomic.AddInt32(&c.countdown, -1) == 0 {
+		panic("clock bug")
+	}
+	return c.Clock.Now()
+}
+
+func (c *panicClock) arm(n int32) {
+	atomic.StoreInt32(&c.countdown, n)
+}
+
+func TestEventBus_LoopRecoverOutsideDispatcher(t *testing.T) {
+	logger := &recordLogger{}
+	clock := &panicClock{Clock: NewFakeClock(time.Unix(0, 0))}
+	events := New(WithLogger(logger), WithClock(clock))
+	got := make(chan string, 2)
+	assert.NoError(t, events.On("boom", func() { got <- "boom" }))
+	assert.NoError(t, events.On("ok", func() { got <- "ok" }))
+
+	events.Pause()
+	id, err := events.SendTracked("boom")
+	assert.NoError(t, err)
+	// 第一次 Now 在出队时调用，第二次在统计排队时长时 panic
+	clock.arm(2)
+	events.Resume()
+	assert.Eventually(t, func() bool { return len(logger.Lines()) == 1 }, time.Second, time.Millisecond)
+	assert.Equal(t, []string{"[LOOP PANIC RECOVER] clock bug"}, logger.Lines())
+	done, err := events.Result(id)
+	assert.True(t, done)
+	assert.Equal(t, ErrDiscarded, err)
+
+	assert.NoError(t, events.Send("ok"))
+	assert.Equal(t, "ok", <-got)
+	events.Close()
+	assert.Len(t, got, 0)
+	assert.Equal(t, int64(0), events.Stats().InFlight)
+}
@@ -0,0 +1,39 @@
+package eventbus
+
+import (
+	"fmt"
+	"io"
+)
+
+// Dump 向 w 输出可读的订阅器状态报告：关闭状态、统计、每个事件的订阅及调用次数
+// 报告在同一把读锁内生成，注册表部分是一致的快照，可以用于 /debug 页面
+func (p *EventBus) Dump(w io.Writer) {
+	p.mu.RLock()
+	infos := p.snapshotLocked()
+	stats := p.Stats()
+	p.mu.RUnlock()
+
+	fmt.Fprintf(w, "closed: %t\n", p.isClosed() || p.isDone())
+	fmt.Fprintf(w, "sent: %d failed: %d queue: %d in_flight: %d\n",
+		stats.Sent, stats.Failed, stats.QueueDepth, stats.InFlight)
+	counts := map[string]int{}
+	for _, info := range infos {
+		counts[info.Key]++
+	}
+	for i, info := range infos {
+		if i == 0 || infos[i-1].Key != info.Key {
+			fmt.Fprintf(w, "event %s handlers: %d\n", info.Key, counts[info.Key])
+		}
+		fmt.Fprintf(w, "  handler %d once: %t calls: %d\n", info.ID, info.Once, info.CallTimes)
+	}
+}
+
+// isDone 判断是否已经调用了 Close
+func (p *EventBus) isDone() bool {
+	select {
+	case <-p.done:
+		return true
+	default:
+		return false
+	}
+}
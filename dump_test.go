@@ -0,0 +1,33 @@
+package eventbus
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventBus_Dump(t *testing.T) {
+	events := New()
+	events.Pause()
+	assert.NoError(t, events.On("order.paid", func() {}))
+	assert.NoError(t, events.Once("user.login", func(string) {}))
+	_, err := events.Subscribe("order.paid", func() {})
+	assert.NoError(t, err)
+	assert.NoError(t, events.Send("order.paid"))
+
+	var buf bytes.Buffer
+	events.Dump(&buf)
+	out := buf.String()
+	assert.Contains(t, out, "closed: false")
+	assert.Contains(t, out, "sent: 1 failed: 0 queue: 1 in_flight: 0")
+	assert.Contains(t, out, "event order.paid handlers: 2")
+	assert.Contains(t, out, "event user.login handlers: 1")
+	assert.Contains(t, out, "once: true calls: 0")
+
+	events.Close()
+	buf.Reset()
+	events.Dump(&buf)
+	assert.Contains(t, buf.String(), "closed: true")
+	assert.Contains(t, buf.String(), "once: false calls: 1")
+}
@@ -0,0 +1,28 @@
+package eventbus
+
+// OnEach 注册逐个处理切片元素的订阅，发送 []T 时对每个元素调用一次 call，同一事件允许注册多个订阅
+// 生产者可以一次发送一批数据，消费者逐条处理。入参不是一个 []T 时返回 ErrArgsNotMatch
+func OnEach[T any](p *EventBus, eventKey string, call func(T)) error {
+	return OnEachLimit(p, eventKey, 0, call)
+}
+
+// OnEachLimit 同 OnEach，切片元素超过 limit 时不调用并返回 ErrEachLimit，limit 小于等于 0 时不限制
+func OnEachLimit[T any](p *EventBus, eventKey string, limit int, call func(T)) error {
+	e := &event{key: eventKey, call: call, raw: func(args []interface{}) error {
+		if len(args) != 1 {
+			return ErrArgsNotMatch
+		}
+		items, ok := args[0].([]T)
+		if !ok {
+			return ErrArgsNotMatch
+		}
+		if limit > 0 && len(items) > limit {
+			return ErrEachLimit
+		}
+		for _, item := range items {
+			call(item)
+		}
+		return nil
+	}}
+	return p.on(e, false)
+}
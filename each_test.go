@@ -0,0 +1,27 @@
+package eventbus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOnEach(t *testing.T) {
+	events := New()
+	defer events.Close()
+	var got []int
+	assert.NoError(t, OnEach(events, "ids", func(id int) { got = append(got, id) }))
+	assert.NoError(t, events.SendSync("ids", []int{1, 2, 3}))
+	assert.Equal(t, []int{1, 2, 3}, got)
+	assert.Equal(t, ErrArgsNotMatch, events.SendSync("ids", []string{"a"}))
+}
+
+func TestOnEachLimit(t *testing.T) {
+	events := New()
+	defer events.Close()
+	var calls int
+	assert.NoError(t, OnEachLimit(events, "ids", 2, func(id int) { calls++ }))
+	assert.Equal(t, ErrEachLimit, events.SendSync("ids", []int{1, 2, 3}))
+	assert.NoError(t, events.SendSync("ids", []int{1, 2}))
+	assert.Equal(t, 2, calls)
+}
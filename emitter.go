@@ -2,11 +2,14 @@
 package eventbus
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"reflect"
+	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 )
 
 // A Emitter 订阅器接口
@@ -22,137 +25,1215 @@ type Emitter interface {
 }
 
 var (
-	ErrArgsNotMatch = errors.New("the number of input args not match")
-	ErrEventType    = errors.New("event type error")
-	ErrExists       = errors.New("event already exists")
-	ErrNotCallable  = errors.New("event not callable")
-	ErrNotFound     = errors.New("event not found")
-	ErrRuntimePanic = errors.New("event runtime recover a panic")
+	ErrAlreadyFired      = errors.New("once event already fired")
+	ErrArgsNotMatch      = errors.New("the number of input args not match")
+	ErrBusClosed         = errors.New("event bus no longer accepts sends")
+	ErrCircuitOpen       = errors.New("circuit breaker open for the event")
+	ErrDiscarded         = errors.New("send discarded without calling handlers")
+	ErrDuplicateName     = errors.New("handler name already registered for the event")
+	ErrGoroutineLimit    = errors.New("the number of dispatch goroutines exceeds the limit")
+	ErrEachLimit         = errors.New("the number of slice elements exceeds the limit")
+	ErrEventType         = errors.New("event type error")
+	ErrExists            = errors.New("event already exists")
+	ErrFrozen            = errors.New("event registration frozen")
+	ErrHandlerLimit      = errors.New("the number of handlers exceeds the limit")
+	ErrHandlerTimeout    = errors.New("handler did not finish before the send timeout")
+	ErrInvalidInterval   = errors.New("schedule interval must be positive")
+	ErrInvalidShard      = errors.New("shard out of range for the total shards")
+	ErrMigrated          = errors.New("send migrated to another event bus")
+	ErrNonDataArg        = errors.New("event args contain a channel or func")
+	ErrNotCallable       = errors.New("event not callable")
+	ErrNoAck             = errors.New("no handler acknowledged the event")
+	ErrNotFound          = errors.New("event not found")
+	ErrPaused            = errors.New("event bus paused")
+	ErrRecursionDepth    = errors.New("synchronous send recursion too deep")
+	ErrReentrantDeadlock = errors.New("serialized event re-entered without its dispatch context")
+	ErrReturnNotMatch    = errors.New("the number of return values not match")
+	ErrScheduleLimit     = errors.New("the number of pending schedules exceeds the limit")
+	ErrRuntimePanic      = errors.New("event runtime recover a panic")
+	ErrUnauthorized      = errors.New("caller not allowed to register the event")
+
+	// StopPropagation 订阅返回该错误时，同一次发送中排在后面的订阅不再调用
+	StopPropagation = errors.New("stop propagation")
 )
 
 // a event 事件，保存了事件的类型，名称和调用方法
 type event struct {
+	id        uint64
 	key       string
 	once      bool
 	call      interface{}
 	argsNums  int
 	callTimes int32
+	// raw 内部使用的调用方法，直接接收全部入参，不做反射和参数个数校验
+	raw func(args []interface{}) error
+	// rawCtx 不为空时代替 raw 调用，同时接收发送方传入的 context，不随本次执行结束取消，直接调用时为 nil
+	rawCtx func(ctx context.Context, args []interface{}) error
+	// adapter 调用前转换入参，使订阅的参数形式与发送方解耦
+	adapter func(args []interface{}) []interface{}
+	// injects 由订阅器注入的前置参数，不计入 argsNums
+	injects []injectKind
+	// variadic 调用方法最后一个参数是可变参数
+	variadic bool
+	// withKey 调用方法的第一个参数接收实际发送的事件
+	withKey bool
+	// boxing 入参个数不匹配时尝试在结构体和字段之间转换
+	boxing bool
+	// version 订阅的事件版本，0 为默认版本
+	version int
+	// ready 回放结束后关闭，之前的实时发送等待回放完成
+	ready   chan struct{}
+	replayN int
+	// group 消费组，同一组的订阅每次发送只调用其中一个
+	group string
+	// touch 每次调用前执行，OnWithTTL 用来重置过期时间
+	touch func()
+	// fast 注册时按签名选出的常见签名快速调用，入参类型不符时返回 false，退回反射调用
+	fast func(args []interface{}) (bool, error)
+	// signal 没有入参和返回值的调用方法，直接调用，不构造入参也不经过 fast
+	signal func()
+	// bus 订阅所在的订阅器，注入 *EventBus 参数时使用
+	bus *EventBus
+	// lazy 首次调用时生成调用方法的工厂，生成的订阅缓存在 target 中
+	lazy     func() interface{}
+	lazyOnce sync.Once
+	target   *event
+	lazyErr  error
+	// fired once 订阅已经被某次发送抢占
+	fired int32
+	// rearm 调用后不注销，保持休眠直到 ResetOnce 重新启用
+	rearm bool
+	// cache 反射调用需要的类型信息，首次调用或 Warmup 时生成
+	cacheOnce sync.Once
+	cache     *callCache
+	// serial 调用期间持有的事件锁，OnSerialized 注册的订阅同一时刻只有一个调用
+	serial *serialMutex
+	// name OnNamed 注册的订阅名称，同一事件内唯一
+	name string
+	// params WithArgTypeCheck 开启时注册时缓存的参数类型，不含注入的参数
+	params []reflect.Type
+	// cond OnIf 的条件，发送时为 false 的订阅不调用
+	cond func() bool
+	// registered 注册时间，用于发现长期存在或泄漏的订阅
+	registered time.Time
+	// shard OnShard 订阅负责的分片，shards 为分片总数，0 表示不分片
+	shard, shards int
+	// meta OnWithMeta 附加的元数据，注册后只读
+	meta map[string]interface{}
 }
 
-// Call 事件执行方法
+// Call 事件执行方法，调用方法最后一个返回值是 error 时作为执行结果返回
 func (e *event) Call(args []interface{}) (err error) {
+	return e.run(nil, args)
+}
+
+// run 在一次调用的上下文中执行事件
+func (e *event) run(d *dispatch, args []interface{}) (err error) {
+	out, err := e.invoke(d, args, true)
+	if err != nil || len(out) == 0 {
+		return err
+	}
+	if last, ok := out[len(out)-1].Interface().(error); ok {
+		return last
+	}
+	return nil
+}
+
+// isRaw 订阅直接接收全部入参，不做反射和参数个数校验
+func (e *event) isRaw() bool {
+	return e.raw != nil || e.rawCtx != nil
+}
+
+// logger 返回订阅所属订阅器的日志，未注册的订阅使用默认日志
+func (e *event) logger() Logger {
+	if e.bus == nil {
+		return stdLogger{}
+	}
+	return e.bus.logger
+}
+
+// invoke 调用事件并返回调用方法的返回值，fast 为 true 时允许使用快速路径，此时不返回调用方法的返回值
+func (e *event) invoke(d *dispatch, args []interface{}, fast bool) (out []reflect.Value, err error) {
 	atomic.AddInt32(&e.callTimes, 1)
-	// 构造入参
-	f := reflect.ValueOf(e.call)
-	in := make([]reflect.Value, f.Type().NumIn())
-	for k, v := range args {
-		in[k] = reflect.ValueOf(v)
+	if e.touch != nil {
+		e.touch()
 	}
 	defer func() {
 		rec := recover()
 		if rec != nil {
-			fmt.Printf("[PANIC RECOVER] call %s panic: %s\n", e.key, rec)
-			err = ErrRuntimePanic
+			stack := capturePanicStack()
+			e.recovered(rec, stack)
+			err = &PanicError{Value: rec, Key: e.key, stack: stack}
 		}
 	}()
-	f.Call(in)
-	return
+	if e.signal != nil && e.adapter == nil {
+		// 信号类事件，多余的入参被忽略
+		e.signal()
+		return nil, nil
+	}
+	if e.lazy != nil {
+		return e.invokeLazy(d, args, fast)
+	}
+	if e.adapter != nil {
+		args = e.adapter(args)
+		if e.argsNums >= 0 && !e.fits(args, false) {
+			return nil, ErrArgsNotMatch
+		}
+	}
+	if e.rawCtx != nil {
+		var ctx context.Context
+		if d != nil {
+			// 批量订阅暂存的发送在执行结束后才输出
+			ctx = detach(d.parent)
+		}
+		return nil, e.rawCtx(ctx, args)
+	}
+	if e.raw != nil {
+		return nil, e.raw(args)
+	}
+	if e.boxing && !e.fits(args, false) {
+		if boxed, ok := e.rebox(args); ok {
+			args = boxed
+		}
+	}
+	// 多余的入参被忽略
+	if !e.variadic && len(args) > e.argsNums {
+		args = args[:e.argsNums]
+	}
+	if fast && e.fast != nil {
+		if ok, err := e.fast(args); ok {
+			return nil, err
+		}
+	}
+	// 构造入参
+	c := e.cached()
+	in := make([]reflect.Value, len(e.injects)+len(args))
+	if len(e.injects) > 0 {
+		if d == nil {
+			d = &dispatch{key: e.key}
+		}
+		for k, kind := range e.injects {
+			if kind == injectBus {
+				in[k] = reflect.ValueOf(e.bus)
+				continue
+			}
+			in[k] = d.inject(kind)
+		}
+	}
+	for k, v := range args {
+		i := len(e.injects) + k
+		if v == nil {
+			// nil 无法直接反射，使用参数类型的零值
+			in[i] = reflect.Zero(c.param(i))
+		} else {
+			in[i] = reflect.ValueOf(v)
+		}
+	}
+	return c.fn.Call(in), nil
+}
+
+// paramType 返回第 i 个入参的类型，可变参数返回元素类型
+func paramType(t reflect.Type, i int) reflect.Type {
+	if t.IsVariadic() && i >= t.NumIn()-1 {
+		return t.In(t.NumIn() - 1).Elem()
+	}
+	return t.In(i)
+}
+
+// accept 判断入参个数是否匹配，argsNums 小于 0 时接受任意个数
+// 设置了 adapter 的订阅在转换后再校验，ignoreExtra 为 true 时允许多于订阅需要的入参
+func (e *event) accept(args []interface{}, ignoreExtra bool) bool {
+	if e.argsNums < 0 || e.adapter != nil || e.fits(args, ignoreExtra) {
+		return true
+	}
+	if e.boxing {
+		_, ok := e.rebox(args)
+		return ok
+	}
+	return false
+}
+
+// fits 校验入参个数，可变参数可以接收零个或多个入参
+func (e *event) fits(args []interface{}, ignoreExtra bool) bool {
+	if e.variadic {
+		return len(args) >= e.argsNums-1
+	}
+	return len(args) == e.argsNums || (ignoreExtra && len(args) > e.argsNums)
 }
 
 func (e *event) String() string {
 	return fmt.Sprintf("{key: %s, once: %t, callTimes: %d}", e.key, e.once, e.callTimes)
 }
 
+// a sender 一次发送，按注册顺序依次调用同一事件下的订阅
 type sender struct {
-	e    *event
-	args []interface{}
+	id       uint64
+	key      string
+	events   []*event
+	args     []interface{}
+	priority int
+	enqueued time.Time
+	// ctx 发送方传入的 context，订阅接收的 context 由它派生
+	ctx context.Context
+	// runCtx 执行期间由 ctx 派生的可以取消的 context，不为空时代替 ctx
+	runCtx context.Context
+	// cancel 调用结束后释放 ctx，SendBefore 设置
+	cancel context.CancelFunc
+	// dequeued 出队时关闭，仅在 ctx 可以取消时创建
+	dequeued chan struct{}
+	// version 发送的事件版本
+	version int
+	// replay 回放历史的发送，不等待订阅的 ready
+	replay bool
+	// sync 同步发送的状态，异步发送为空
+	sync *syncState
+	// notify 为 true 时在入队前创建 results
+	notify bool
+	// results 每个订阅调用结束后写入结果，全部调用结束后关闭
+	results chan HandlerResult
+	// trace 每个订阅调用结束后回调，用于输出调试信息
+	trace func(e *event, cost time.Duration, err error)
+	// ack SendAck 等待的确认，订阅通过注入的 context 调用 Ack
+	ack *ackState
+	// bubble 冒泡发送，levels 与 events 一一对应，记录订阅所在的层级，0 为事件自身
+	bubble bool
+	levels []int
+	// buffered 已经被 BufferKey 缓存过，刷新时不再缓存
+	buffered bool
+	// tracked SendTracked 的发送，调用结束后把结果写入 sendResults
+	tracked bool
+	// walSeq 发送在预写日志中的序号，0 表示没有写入
+	walSeq uint64
+	// filter 不为空时只调用满足条件的订阅
+	filter func(e *event) bool
+	// tracer 开启追踪时为本次发送开始 span，sampled 不为空时决定是否采样
+	tracer  Tracer
+	sampled func() bool
+}
+
+// level 返回第 i 个订阅所在的层级
+func (s *sender) level(i int) int {
+	if s.levels == nil {
+		return 0
+	}
+	return s.levels[i]
 }
 
+// Call 执行本次发送，冒泡发送时 events 按层级排列，停止传播后跳过更上级的订阅
 func (s *sender) Call() (err error) {
-	return s.e.Call(s.args)
+	parent := s.ctx
+	if s.runCtx != nil {
+		parent = s.runCtx
+	}
+	d := &dispatch{id: s.id, key: s.key, priority: s.priority, parent: parent, prop: &Propagation{}, sync: s.sync, ack: s.ack}
+	if s.results != nil {
+		defer close(s.results)
+	}
+	if s.cancel != nil {
+		defer s.cancel()
+	}
+	if span := s.startSpan(d); span != nil {
+		defer span.End()
+		trace := s.trace
+		s.trace = func(e *event, cost time.Duration, err error) {
+			if trace != nil {
+				trace(e, cost, err)
+			}
+			recordSpan(span, e, cost, err)
+		}
+	}
+	stopped := -1
+	for i, e := range s.events {
+		if d.prop.immediate {
+			break
+		}
+		if d.prop.stopped && stopped < 0 {
+			stopped = s.level(i - 1)
+		}
+		if stopped >= 0 && s.level(i) > stopped {
+			continue
+		}
+		if e.ready != nil && !s.replay {
+			<-e.ready
+		}
+		start := time.Now()
+		callErr := s.runSerial(e, d)
+		if s.trace != nil {
+			s.trace(e, time.Since(start), callErr)
+		}
+		if s.results != nil {
+			s.results <- HandlerResult{HandlerID: e.id, Err: callErr}
+		}
+		if errors.Is(callErr, StopPropagation) {
+			d.prop.StopImmediatePropagation()
+			continue
+		}
+		if callErr != nil && err == nil {
+			err = callErr
+		}
+	}
+	return
 }
 
 // EventBus 事件订阅器
 type EventBus struct {
-	// events 储存结构类似 map[string]*event,
+	// events 储存结构类似 map[string][]*event, 按注册顺序保存同一事件的全部订阅
+	// 切片只读，注册变更时整体替换
 	events sync.Map
-	sender chan sender
-	done   chan bool
+	// mu 串行化注册变更，Send 查找时持有读锁，保证看到的注册表是一致的
+	mu sync.RWMutex
+	// seq 订阅ID生成器
+	seq uint64
+	// sendSeq 发送ID生成器
+	sendSeq uint64
+	queue   *queue
+	done    chan bool
+	// stopped Loop 退出后关闭
+	stopped   chan struct{}
+	closeOnce sync.Once
+	// closeMu 串行关闭与发送的准入，admitting 为 WithAtomicClose 下已经准入尚未入队的发送
+	closeMu     sync.RWMutex
+	admitting   sync.WaitGroup
+	atomicClose bool
+	// running 正在执行的调用
+	running sync.WaitGroup
+	// slots 限制排队和执行中的发送总数，WithMaxGoroutines 开启
+	slots    chan struct{}
+	failFast bool
+	// pool 固定数量的工作协程，WithWorkers 开启，为空时每个发送使用一个新协程
+	pool          *pool
+	workers       int
+	stickyWorkers bool
+	// dispatcher 自定义的发送执行方式，WithDispatcher 开启
+	dispatcher Dispatcher
+	// stats 发送和调用的统计
+	stats counters
+	// outcomes 每个事件最近的执行结果
+	outcomes outcomes
+	// paused 暂停调用，发送继续放入队列
+	paused int32
+	// pausePolicy 暂停期间异步发送的处理方式
+	pausePolicy PausePolicy
+	// shedThreshold 开启过载保护时队列积压的阈值，超过后 shedFn 接收丢弃的低优先级发送
+	shedThreshold int
+	shedFn        func(key string, args []interface{})
+	// keyLocks WithKeyLock 使用的按事件分段的锁
+	keyLocks [lockStripes]sync.Mutex
+	// serialLocks OnSerialized 使用的事件锁，每个事件一把
+	serialLocks sync.Map
+	// ignoreExtraArgs 忽略多于订阅需要的入参
+	ignoreExtraArgs bool
+	// strictUnknown Send 未注册的事件时 panic
+	strictUnknown bool
+	// firehoseFirst 全量订阅先于事件自身的订阅调用
+	firehoseFirst bool
+	// wildcardFirst 通配订阅先于事件自身的订阅调用
+	wildcardFirst bool
+	// expectedKeys 预计注册的事件个数
+	expectedKeys int
+	// inheritPriority 订阅中继续发送的事件继承触发事件的优先级
+	inheritPriority bool
+	// schedules 按事件登记尚未结束的 SendAfter、SendEvery
+	smu       sync.Mutex
+	schedules map[string]map[*schedule]struct{}
+	// scheduled 尚未结束的定时发送个数，maxSchedules 为 WithMaxSchedules 设置的上限
+	scheduled    int
+	maxSchedules int
+	// autoSync 返回 error 的订阅在发送方协程中同步调用
+	autoSync bool
+	// singleHandler 兼容旧版本，On 等注册方法在同一事件已有订阅时返回 ErrExists
+	singleHandler bool
+	// argTypeCheck 发送时校验入参类型
+	argTypeCheck bool
+	// argBoxing 入参个数不匹配时在结构体和字段之间转换
+	argBoxing bool
+	// fallback 事件没有订阅时调用的兜底订阅，由 mu 保护
+	fallback *event
+	// patterns 已注册的通配事件，由 mu 保护
+	patterns []string
+	// anys 接收全部发送的订阅，由 mu 保护
+	anys []*event
+	// handlerCount 全部事件的订阅与全量订阅的总数，由 mu 保护
+	handlerCount int
+	maxHandlers  int
+	// protected 受保护的事件前缀及允许注册的包
+	protected     map[string]map[string]bool
+	callerPackage func() string
+	// waiters 等待订阅的信号，事件注册后关闭对应 channel
+	wmu     sync.Mutex
+	waiters map[string]chan struct{}
+	// closed 关闭或迁移之后不再接受新的发送
+	closed int32
+	// frozen 冻结后不再允许注册和移除事件
+	frozen int32
+	// retained 每个事件最近一次保留的入参
+	rmu      sync.RWMutex
+	retained map[string][]interface{}
+	// stickyMu 串行 SendRetained 与 OnSticky，保证新订阅收到的保留值不重复也不遗漏
+	stickyMu sync.Mutex
+	// cursors 消费组轮询的位置，key 为 事件\x00组名
+	cursors sync.Map
+	// history 每个事件最近的发送记录，WithHistory 开启
+	history *history
+	// historyArgLimit 历史中每个入参保留的字节数上限
+	historyArgLimit int
+	// sendErrors 发送失败时调用的订阅，由 mu 保护
+	sendErrors []func(eventKey string, err error)
+	// batchMaxBytes 批量订阅的批次字节数上限
+	batchMaxBytes int
+	// batchers 批量订阅，关闭时输出剩余的批次，由 mu 保护
+	batchers []*batcher
+	// federation 按前缀转发到其他订阅器的路由
+	federation *Federation
+	// dedup 合并窗口内内容相同的发送
+	dedup *dedup
+	// deadLetters 调用失败的异步发送，WithDeadLetterStore 开启
+	deadLetters DeadLetterStore
+	// idempotency SendIdempotent 使用的幂等键存储
+	idempotency IdempotencyStore
+	// validator 校验结构体入参
+	validator func(interface{}) error
+	// replySeq 应答事件ID生成器
+	replySeq uint64
+	// replyTimeout RequestAsync 等待应答的时间
+	replyTimeout time.Duration
+	// clock 时钟，默认使用系统时间
+	clock Clock
+	// codec 转发事件的编解码方式，默认使用 JSON
+	codec Codec
+	// logger 输出日志，默认输出到标准输出
+	logger Logger
+	// tracer 分布式追踪，WithTracer 开启
+	tracer Tracer
+	// sampled 决定一次发送是否开始 span，WithTraceSampling 开启
+	sampled func() bool
+	// panicHook 订阅 panic 时调用
+	panicHook func(eventKey string, value interface{}, stack *PanicStack)
+	// silentPanics 订阅 panic 时不输出日志
+	silentPanics bool
+	// shardArg OnShard 计算分片使用的入参位置
+	shardArg int
+	// traces 正在跟踪的事件及截止时间
+	tmu    sync.Mutex
+	traces map[string]time.Time
+	// middlewares 包装每次订阅调用的中间件
+	middlewares middlewares
+	// buffers BufferKey 缓存的发送，按事件保存
+	bmu     sync.Mutex
+	buffers map[string][]*sender
+	// wal 预写日志，WithWAL 开启
+	wal *wal
+	// sharedCounters Counter 返回的计数器，按名称保存
+	sharedCounters sync.Map
+	// sendResults SendTracked 的发送结果，按发送ID保存
+	sendResults *sendResults
+	// rejectNonData 入参包含 channel 或 func 时拒绝发送，nonDataWarned 记录已经输出过警告的事件
+	rejectNonData bool
+	nonDataWarned sync.Map
+	// breaker WithCircuitBreaker 按事件统计连续失败
+	breaker *breaker
+	// dispatching Loop 正在交给执行方的发送，只在 Loop 协程中读写
+	dispatching *Job
+	// closeFlush 关闭时写入最终状态的 WithCloseFlush
+	closeFlush *closeFlush
+	// waits 最近的排队时间
+	waits reservoir
+	// keyMetrics 按事件统计的调度次数和排队时间
+	keyMetrics keyMetrics
+	// inflight 正在执行的发送
+	inflight inflight
+	// watchdog 检测执行时间过长的发送
+	watchdog watchdog
+	// metrics 定时发出统计的事件配置
+	metrics struct {
+		key      string
+		interval time.Duration
+	}
 }
 
 // On 注册订阅器，注册之后将实例放入 events中。在Send中调用
+// 同一事件允许注册多个订阅，按注册顺序依次调用；WithSingleHandlerMode 下重复注册返回 ErrExists
 func (p *EventBus) On(eventKey string, call interface{}) error {
-	return p.on(&event{key: eventKey, call: call})
+	return p.on(&event{key: eventKey, call: call}, true)
 }
 
 // Once 仅用一次注册订阅器，注册之后将实例放入 events中。在Send中调用，调用之后立即移除掉
 func (p *EventBus) Once(eventKey string, call interface{}) error {
-	return p.on(&event{key: eventKey, once: true, call: call})
+	return p.on(&event{key: eventKey, once: true, call: call}, true)
+}
+
+// on 注册事件，unique 为 true 且开启 WithSingleHandlerMode 时同一事件只允许一个订阅
+func (p *EventBus) on(e *event, unique bool) error {
+	if p.isFrozen() {
+		return ErrFrozen
+	}
+	if err := p.setup(e); err != nil {
+		return err
+	}
+	if err := p.authorize(e.key); err != nil {
+		return err
+	}
+	replay, err := p.register(e, unique)
+	if err != nil {
+		return err
+	}
+	if e.ready != nil {
+		p.replay(e, replay)
+	}
+	p.notifySub(e.key)
+	return nil
 }
 
-func (p *EventBus) on(e *event) error {
+// register 把 e 加入注册表，带回放的订阅同时取出需要回放的历史发送
+// 读取历史与注册在同一把锁内，回放不会与实时发送重复或遗漏
+func (p *EventBus) register(e *event, unique bool) ([][]interface{}, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	handlers := p.handlers(e.key)
+	if unique && p.singleHandler && len(versioned(handlers, e.version)) > 0 {
+		return nil, ErrExists
+	}
+	if p.maxHandlers > 0 && p.handlerCount >= p.maxHandlers {
+		return nil, ErrHandlerLimit
+	}
+	if e.name != "" && named(handlers, e.name) != nil {
+		return nil, ErrDuplicateName
+	}
+	e.registered = p.clock.Now()
+	p.put(e.key, handlers, append(handlers[:len(handlers):len(handlers)], e))
+	if e.ready != nil {
+		return p.history.last(e.key, e.replayN), nil
+	}
+	return nil, nil
+}
+
+// setup 校验调用方法并分配订阅ID
+func (p *EventBus) setup(e *event) error {
+	e.id = atomic.AddUint64(&p.seq, 1)
+	e.bus = p
+	if e.isRaw() || e.lazy != nil {
+		e.argsNums = -1
+		return nil
+	}
 	f := reflect.ValueOf(e.call)
 	if f.Kind() != reflect.Func {
 		return ErrNotCallable
 	}
 	// 初始化入参，每次send 都会从入参中重新填充
-	e.argsNums = f.Type().NumIn()
-	if _, ok := p.events.LoadOrStore(e.key, e); ok {
-		return ErrExists
+	t := f.Type()
+	start := 0
+	if e.withKey {
+		if t.NumIn() == 0 || t.In(0) != stringType {
+			return ErrNotCallable
+		}
+		e.injects = []injectKind{injectKey}
+		start = 1
+	}
+	e.injects = append(e.injects, injectsOf(t, start)...)
+	e.variadic = t.IsVariadic()
+	e.argsNums = t.NumIn() - len(e.injects)
+	e.boxing = p.argBoxing
+	if p.argTypeCheck {
+		e.params = paramTypes(t, len(e.injects))
+	}
+	if len(e.injects) == 0 {
+		e.signal, _ = e.call.(func())
+		e.fast = fastCall(e.call)
 	}
 	return nil
 }
 
-// Send 调用事件，执行后注销once事件
-func (p *EventBus) Send(eventKey string, args ...interface{}) error {
+// handlers 返回事件下的全部订阅，返回的切片不可修改
+func (p *EventBus) handlers(eventKey string) []*event {
 	m, ok := p.events.Load(eventKey)
 	if !ok {
-		return ErrNotFound
+		return nil
 	}
-	e, ok := m.(*event)
+	handlers, _ := m.([]*event)
+	return handlers
+}
+
+// put 使用 handlers 替换事件原有的订阅 old，并更新订阅总数，调用方需持有 mu
+func (p *EventBus) put(eventKey string, old, handlers []*event) {
+	if len(handlers) == 0 {
+		p.events.Delete(eventKey)
+	} else {
+		p.events.Store(eventKey, handlers)
+	}
+	p.handlerCount += len(handlers) - len(old)
+	if isPattern(eventKey) && (len(old) == 0) != (len(handlers) == 0) {
+		p.indexPatterns()
+	}
+}
+
+// removeIf 移除事件下满足 match 的订阅，全部移除后删除事件
+func (p *EventBus) removeIf(eventKey string, match func(e *event) bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	handlers := p.handlers(eventKey)
+	rest := make([]*event, 0, len(handlers))
+	for _, e := range handlers {
+		if !match(e) {
+			rest = append(rest, e)
+		}
+	}
+	if len(rest) != len(handlers) {
+		p.put(eventKey, handlers, rest)
+	}
+}
+
+// claimOnce 抢占 handlers 中的 once 和 OnFirstAfterReset 订阅，并发发送时只有一个发送能够抢到，
+// 被其他发送抢先的订阅被剔除，全部被剔除时返回 ErrAlreadyFired
+func claimOnce(handlers []*event) ([]*event, error) {
+	claimed := handlers
+	for i, e := range handlers {
+		if !(e.once || e.rearm) || atomic.CompareAndSwapInt32(&e.fired, 0, 1) {
+			if len(claimed) < len(handlers) {
+				claimed = append(claimed, e)
+			}
+			continue
+		}
+		if len(claimed) == len(handlers) {
+			claimed = append(make([]*event, 0, len(handlers)), handlers[:i]...)
+		}
+	}
+	if len(claimed) == 0 {
+		return nil, ErrAlreadyFired
+	}
+	return claimed, nil
+}
+
+// removeOnce 注销已经发出的 once 订阅，每个 once 订阅从自身的事件中移除
+func (p *EventBus) removeOnce(sent []*event) {
+	var removed []string
+	for _, e := range sent {
+		if !e.once || containsKey(removed, e.key) {
+			continue
+		}
+		removed = append(removed, e.key)
+		p.removeIf(e.key, func(h *event) bool {
+			return h.once && containsEvent(sent, h)
+		})
+	}
+}
+
+func containsKey(keys []string, key string) bool {
+	for _, k := range keys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+func containsEvent(events []*event, e *event) bool {
+	for _, h := range events {
+		if h == e {
+			return true
+		}
+	}
+	return false
+}
+
+// waitSub 返回一个在 eventKey 被注册时关闭的 channel
+func (p *EventBus) waitSub(eventKey string) <-chan struct{} {
+	p.wmu.Lock()
+	defer p.wmu.Unlock()
+	if p.waiters == nil {
+		p.waiters = make(map[string]chan struct{})
+	}
+	ch, ok := p.waiters[eventKey]
 	if !ok {
-		// 永远不会发生
-		return ErrEventType
+		ch = make(chan struct{})
+		p.waiters[eventKey] = ch
+	}
+	return ch
+}
+
+// notifySub 唤醒等待 eventKey 注册的调用方
+func (p *EventBus) notifySub(eventKey string) {
+	p.wmu.Lock()
+	defer p.wmu.Unlock()
+	if ch, ok := p.waiters[eventKey]; ok {
+		close(ch)
+		delete(p.waiters, eventKey)
+	}
+}
+
+// Send 调用事件，执行后注销once事件
+func (p *EventBus) Send(eventKey string, args ...interface{}) error {
+	err := p.send(&sender{key: eventKey, args: args})
+	if err == ErrNotFound && p.strictUnknown {
+		panic(fmt.Errorf("%w: %s", ErrNotFound, eventKey))
+	}
+	return err
+}
+
+// send 查找订阅并校验入参，成功后放入队列等待调用，失败时通知 OnSendError
+func (p *EventBus) send(s *sender) (err error) {
+	defer func() { p.sendError(s.key, err) }()
+	return p.trySend(s)
+}
+
+// trySend 同 send，失败时不通知 OnSendError
+func (p *EventBus) trySend(s *sender) error {
+	if p.atomicClose {
+		if !p.admit() {
+			return ErrBusClosed
+		}
+		defer p.admitting.Done()
+	}
+	if p.isClosed() {
+		return ErrBusClosed
+	}
+	if target := p.federation.target(s.key); target != nil && target != p {
+		return target.send(s)
 	}
-	if len(args) != e.argsNums {
-		return ErrArgsNotMatch
+	if ok, err := p.admitPaused(s); !ok {
+		return err
 	}
-	if e.once {
-		p.Remove(eventKey)
+	if p.buffer(s) || p.shed(s) {
+		return nil
 	}
-	p.sender <- sender{e: e, args: args}
+	dup, err := p.prepare(s)
+	if err != nil {
+		return err
+	}
+	if s.notify {
+		s.results = make(chan HandlerResult, len(s.events))
+		if dup {
+			// 被合并的发送没有任何调用，直接关闭结果
+			close(s.results)
+		}
+	}
+	if dup {
+		return nil
+	}
+	if p.autoSync && !s.notify && s.ack == nil && !s.bubble {
+		return p.splitSend(s)
+	}
+	if err := p.enqueue(s); err != nil {
+		return err
+	}
+	p.watchCancel(s)
 	return nil
 }
 
-// Remove 移除事件
+// prepare 校验入参并查找订阅，结果写入 s.events。窗口内重复的发送返回 dup 为 true
+// 同步发送不占用 WithMaxGoroutines 的名额
+func (p *EventBus) prepare(s *sender) (dup bool, err error) {
+	if err := p.validate(s.key, s.args); err != nil {
+		return false, err
+	}
+	if err := p.breaker.allow(s.key, p.clock.Now()); err != nil {
+		return false, err
+	}
+	if p.dedup != nil {
+		hash, fresh := p.dedup.claim(s.key, s.args)
+		if !fresh {
+			// 窗口内相同的发送合并为一次
+			return true, nil
+		}
+		defer func() {
+			if err != nil {
+				p.dedup.release(hash)
+			}
+		}()
+	}
+	// 查找订阅和记录历史在同一把读锁内，带回放的注册不会重复或遗漏
+	p.mu.RLock()
+	handlers, err := p.orderedLocked(s, pickNext)
+	if p.history != nil && (err == nil || err == ErrNotFound) {
+		p.history.push(s.key, truncateArgs(s.args, p.historyArgLimit))
+	}
+	p.mu.RUnlock()
+	if err == nil && s.filter != nil {
+		handlers, err = filtered(handlers, s.filter)
+	}
+	if err != nil {
+		return false, err
+	}
+	all := handlers
+	if handlers = enabled(handlers, s.args); len(handlers) == 0 {
+		// OnIf 的条件都不满足或没有对应的分片，与被合并的发送一样不调用任何订阅
+		return true, nil
+	}
+	if s.sync == nil {
+		if err := p.acquire(); err != nil {
+			return false, err
+		}
+	}
+	if handlers, err = claimOnce(handlers); err != nil {
+		if s.sync == nil {
+			p.release()
+		}
+		return false, err
+	}
+	if s.levels != nil && len(handlers) != len(all) {
+		s.levels = keptLevels(all, handlers, s.levels)
+	}
+	// once 事件的自动注销不受 Freeze 限制
+	p.removeOnce(handlers)
+	s.events = handlers
+	s.tracer, s.sampled = p.tracer, p.sampled
+	return false, nil
+}
+
+// enqueue 放入队列等待调用，与 Close 并发时队列可能已经关闭，此时归还名额并返回 ErrBusClosed
+// 已经分配发送ID的 s 视为已经计数
+func (p *EventBus) enqueue(s *sender) error {
+	counted := s.id != 0
+	if !counted {
+		s.id = atomic.AddUint64(&p.sendSeq, 1)
+	}
+	p.logSend(s)
+	p.startResult(s)
+	if !p.queue.push(s) {
+		p.finishResult(s, ErrBusClosed)
+		p.ackSend(s)
+		p.release()
+		if s.results != nil {
+			close(s.results)
+		}
+		return ErrBusClosed
+	}
+	if !counted {
+		atomic.AddInt64(&p.stats.sent, 1)
+	}
+	return nil
+}
+
+// match 查找事件默认版本的全部订阅并校验入参，pick 决定消费组如何选出订阅
+func (p *EventBus) match(eventKey string, args []interface{}, pick groupPick) ([]*event, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.matchLocked(eventKey, 0, args, pick)
+}
+
+// matchLocked 查找事件指定版本的全部订阅并校验入参，该版本没有订阅时使用默认版本，调用方需持有 mu
+// 事件和通配订阅都没有时使用兜底订阅
+func (p *EventBus) matchLocked(eventKey string, version int, args []interface{}, pick groupPick) ([]*event, error) {
+	handlers, err := p.levelLocked(eventKey, version, args, pick)
+	if err == ErrNotFound && p.fallback != nil {
+		return p.fallbackLocked(args)
+	}
+	return handlers, err
+}
+
+// fallbackLocked 返回兜底订阅，调用方需持有 mu
+func (p *EventBus) fallbackLocked(args []interface{}) ([]*event, error) {
+	if !p.fallback.accept(args, p.ignoreExtraArgs) {
+		return nil, ErrArgsNotMatch
+	}
+	return []*event{p.fallback}, nil
+}
+
+// levelLocked 同 matchLocked，不使用兜底订阅
+func (p *EventBus) levelLocked(eventKey string, version int, args []interface{}, pick groupPick) ([]*event, error) {
+	all := p.handlers(eventKey)
+	handlers := versioned(all, version)
+	if len(handlers) == 0 && version != 0 {
+		handlers = versioned(all, 0)
+	}
+	wild := p.wildcards(eventKey, args)
+	handlers = p.balance(eventKey, handlers, pick)
+	if len(handlers) == 0 && len(wild) == 0 {
+		return nil, ErrNotFound
+	}
+	for _, e := range handlers {
+		if !e.accept(args, p.ignoreExtraArgs) {
+			return nil, ErrArgsNotMatch
+		}
+		if !e.typesMatch(args) {
+			return nil, ErrEventType
+		}
+	}
+	if len(wild) > 0 {
+		if p.wildcardFirst {
+			handlers = append(wild[:len(wild):len(wild)], handlers...)
+		} else {
+			handlers = append(handlers[:len(handlers):len(handlers)], wild...)
+		}
+	}
+	return handlers, nil
+}
+
+// SendWaitSub 调用事件，如果事件尚未注册，最多等待 timeout 直到订阅出现后再调用
+// 超时仍未注册返回 ErrNotFound，用于解决生产者先于消费者启动的问题
+// 等待期间的重试不通知 OnSendError，只报告最终结果
+func (p *EventBus) SendWaitSub(eventKey string, timeout time.Duration, args ...interface{}) (err error) {
+	defer func() { p.sendError(eventKey, err) }()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for {
+		// 先登记等待再尝试发送，避免错过两者之间发生的注册
+		ready := p.waitSub(eventKey)
+		err := p.trySend(&sender{key: eventKey, args: args})
+		if err != ErrNotFound {
+			return err
+		}
+		select {
+		case <-ready:
+		case <-timer.C:
+			return ErrNotFound
+		}
+	}
+}
+
+// Remove 移除事件，Freeze 之后调用无效
 func (p *EventBus) Remove(eventkey string) {
-	p.events.Delete(eventkey)
+	if p.isFrozen() {
+		return
+	}
+	p.mu.Lock()
+	p.put(eventkey, p.handlers(eventkey), nil)
+	p.mu.Unlock()
+}
+
+// RemovePrefix 移除 key 以 prefix 开头的全部事件，用于按命名空间一次注销整个模块的事件
+// 移除在同一把锁内完成，并发的 Send 只会看到移除前或移除后的注册表，Freeze 之后调用无效
+func (p *EventBus) RemovePrefix(prefix string) {
+	if p.isFrozen() {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.events.Range(func(key, value interface{}) bool {
+		if eventKey := key.(string); strings.HasPrefix(eventKey, prefix) {
+			p.put(eventKey, value.([]*event), nil)
+		}
+		return true
+	})
+}
+
+// ReplaceAll 原子替换全部订阅，旧订阅全部移除，新订阅在同一把锁内装载，
+// 并发的 Send 只会看到完整的旧集合或完整的新集合
+// 任一调用方法不合法时返回错误，注册表保持不变
+func (p *EventBus) ReplaceAll(handlers map[string]interface{}) error {
+	if p.isFrozen() {
+		return ErrFrozen
+	}
+	next := make(map[string][]*event, len(handlers))
+	for key, call := range handlers {
+		e := &event{key: key, call: call}
+		if err := p.setup(e); err != nil {
+			return err
+		}
+		if err := p.authorize(key); err != nil {
+			return err
+		}
+		next[key] = []*event{e}
+	}
+	p.mu.Lock()
+	// 全量订阅不随 ReplaceAll 移除，依旧计入订阅总数
+	if p.maxHandlers > 0 && len(next)+len(p.anys) > p.maxHandlers {
+		p.mu.Unlock()
+		return ErrHandlerLimit
+	}
+	p.events.Range(func(key, _ interface{}) bool {
+		p.events.Delete(key)
+		return true
+	})
+	for key, events := range next {
+		p.events.Store(key, events)
+	}
+	p.handlerCount = len(next) + len(p.anys)
+	p.indexPatterns()
+	p.mu.Unlock()
+	for key := range next {
+		p.notifySub(key)
+	}
+	return nil
 }
 
-// Close 发出停止信号
+// HotSwap 用 newCall 替换事件的全部订阅，用于不停机升级订阅
+// 替换前已经发出的发送(包括仍在队列中的)继续调用旧订阅直到结束，之后的发送调用新订阅，不会丢失发送
+// 事件没有订阅时返回 ErrNotFound
+func (p *EventBus) HotSwap(eventKey string, newCall interface{}) error {
+	if p.isFrozen() {
+		return ErrFrozen
+	}
+	e := &event{key: eventKey, call: newCall}
+	if err := p.setup(e); err != nil {
+		return err
+	}
+	if err := p.authorize(eventKey); err != nil {
+		return err
+	}
+	p.mu.Lock()
+	handlers := p.handlers(eventKey)
+	if len(handlers) == 0 {
+		p.mu.Unlock()
+		return ErrNotFound
+	}
+	e.registered = p.clock.Now()
+	p.put(eventKey, handlers, []*event{e})
+	p.mu.Unlock()
+	p.notifySub(eventKey)
+	return nil
+}
+
+// Freeze 冻结注册表，之后 On/Once 返回 ErrFrozen，Remove 不再生效
+// 适合在启动阶段完成注册后调用，保证运行期间事件集合稳定
+func (p *EventBus) Freeze() {
+	atomic.StoreInt32(&p.frozen, 1)
+}
+
+func (p *EventBus) isFrozen() bool {
+	return atomic.LoadInt32(&p.frozen) == 1
+}
+
+// Close 发出停止信号，队列中剩余的事件会被调用，等待全部调用结束后返回
+// 批量订阅中尚未输出的批次在返回前输出，关闭之后的发送返回 ErrBusClosed
 func (p *EventBus) Close() {
-	p.done <- true
-	// FIXME: 是否清理已经发不过来的任务？
+	p.closeOnce.Do(func() {
+		p.closeFlush.capture(p)
+		// 关闭之后的发送返回 ErrBusClosed
+		p.closeMu.Lock()
+		atomic.StoreInt32(&p.closed, 1)
+		p.closeMu.Unlock()
+		close(p.done)
+	})
+	<-p.stopped
+	p.running.Wait()
+	p.mu.RLock()
+	batchers := p.batchers
+	p.mu.RUnlock()
+	for _, b := range batchers {
+		b.flush(b.generation())
+	}
+	p.closeFlush.write(p)
+	if p.wal != nil {
+		p.wal.close()
+	}
 }
 
-// Loop 时间循环，后台消费sender的数据
+// Loop 时间循环，后台按优先级消费队列中的数据
+// 调度逻辑(不包括订阅)panic 时输出日志并重新开始循环，避免订阅器停止调度
 func (p *EventBus) Loop() {
+	defer close(p.stopped)
+	for !p.loop() {
+	}
+}
+
+// loop 消费队列直到关闭，返回 false 表示从 panic 中恢复，需要重新开始
+func (p *EventBus) loop() (closed bool) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			p.logger.Printf("[LOOP PANIC RECOVER] %v", rec)
+			if job := p.dispatching; job != nil {
+				// 交给执行方之前 panic，没有执行的发送被放弃
+				p.dispatching = nil
+				job.Discard()
+			}
+			// 队列中可能还有剩余的发送
+			p.queue.signal()
+		}
+	}()
 	for {
 		select {
 		case <-p.done:
-			break
-		case s := <-p.sender:
-			go s.Call()
+			// 关闭时即使处于暂停状态也调用剩余的事件，之后入队的发送返回 ErrBusClosed
+			p.admitting.Wait()
+			p.queue.close()
+			p.drain(true)
+			p.pool.stop()
+			return true
+		case <-p.queue.ready:
+			p.drain(false)
 		}
 	}
 }
 
+// drain 取出队列中的全部发送并调用，force 为 false 时暂停后停止取出
+func (p *EventBus) drain(force bool) {
+	for force || !p.isPaused() {
+		s, ok := p.queue.pop()
+		if !ok {
+			return
+		}
+		if s.ctx != nil && s.ctx.Err() != nil {
+			// 排队期间已经取消
+			p.finishResult(s, s.ctx.Err())
+			p.ackSend(s)
+			p.release()
+			continue
+		}
+		p.running.Add(1)
+		atomic.AddInt64(&p.stats.inFlight, 1)
+		// 交给执行方之前 panic 时由 loop 放弃该发送
+		job := &Job{ID: s.id, Key: s.key, Args: s.args, Priority: s.priority, EnqueuedAt: s.enqueued, bus: p, s: s}
+		p.dispatching = job
+		p.watchdog.track(s)
+		wait := p.clock.Now().Sub(s.enqueued)
+		p.waits.record(wait)
+		p.keyMetrics.record(s.key, wait)
+		p.traceSender(s)
+		if p.dispatcher != nil {
+			job.DispatchedAt = p.clock.Now()
+			p.dispatcher.Dispatch(job)
+		} else if p.pool != nil {
+			p.pool.submit(s)
+		} else {
+			go job.Run()
+		}
+		p.dispatching = nil
+	}
+}
+
+// execute 执行一次出队的发送并更新统计
+func (p *EventBus) execute(s *sender) {
+	defer p.running.Done()
+	defer p.release()
+	defer atomic.AddInt64(&p.stats.inFlight, -1)
+	defer p.watchdog.untrack(s)
+	defer p.begin(s)()
+	err := s.Call()
+	p.ackSend(s)
+	if err != nil {
+		atomic.AddInt64(&p.stats.failed, 1)
+		p.deadLetter(s, err)
+	}
+	p.recordOutcome(s.key, err)
+	p.finishResult(s, err)
+}
+
+// Option 事件订阅器的构建选项
+type Option func(*EventBus)
+
 // New 构建一个事件订阅器
-func New() *EventBus {
+func New(opts ...Option) *EventBus {
 	bus := EventBus{
-		sender: make(chan sender),
-		done:   make(chan bool),
+		queue:         newQueue(),
+		done:          make(chan bool),
+		stopped:       make(chan struct{}),
+		callerPackage: callerPackage,
+		logger:        stdLogger{},
+		codec:         jsonCodec{},
+		clock:         realClock{},
+		replyTimeout:  defaultReplyTimeout,
+		idempotency:   &memoryIdempotency{keys: make(map[string]struct{})},
+		sendResults:   &sendResults{ttl: defaultResultTTL, results: make(map[uint64]*sendResult)},
+	}
+	for _, opt := range opts {
+		opt(&bus)
+	}
+	bus.presize()
+	bus.openWAL()
+	if bus.workers > 0 && bus.dispatcher == nil {
+		bus.pool = newPool(bus.workers, bus.stickyWorkers, bus.execute)
 	}
+	bus.queue.now = bus.clock.Now
+	if bus.dedup != nil {
+		bus.dedup.now = bus.clock.Now
+	}
+	bus.watchdog.now = bus.clock.Now
 	go bus.Loop()
+	if bus.metrics.interval > 0 {
+		go bus.emitMetrics()
+	}
+	if bus.watchdog.limit > 0 {
+		go bus.watch()
+	}
 	return &bus
 }
@@ -3,7 +3,10 @@ package eventbus
 import (
 	"fmt"
 	"runtime"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -34,7 +37,7 @@ func addOnce(a, b, c int) {
 }
 
 func TestEventBus_On(t *testing.T) {
-	events := New()
+	events := New(WithSingleHandlerMode())
 	defer events.Close()
 	err := events.On("add", add)
 	assert.NoError(t, err)
@@ -43,6 +46,22 @@ func TestEventBus_On(t *testing.T) {
 	assert.EqualError(t, err, ErrExists.Error())
 }
 
+func TestEventBus_OnMultiHandler(t *testing.T) {
+	events := New()
+	got := make(chan int, 3)
+	assert.NoError(t, events.On("add", func(a int) { got <- a }))
+	assert.NoError(t, events.On("add", func(a int) { got <- a * 10 }))
+	assert.NoError(t, events.Once("add", func(a int) { got <- a * 100 }))
+	assert.NoError(t, events.SendSync("add", 1))
+	events.Close()
+	close(got)
+	var received []int
+	for a := range got {
+		received = append(received, a)
+	}
+	assert.Equal(t, []int{1, 10, 100}, received)
+}
+
 func TestEventBus_Once(t *testing.T) {
 	events := New()
 	defer events.Close()
@@ -62,7 +81,6 @@ func TestEventBus_Once(t *testing.T) {
 
 func TestEventBus_Send(t *testing.T) {
 	events := New()
-	defer events.Close()
 	events.On("add", add)
 
 	err := events.Send("add", 1, 2, 3)
@@ -73,8 +91,9 @@ func TestEventBus_Send(t *testing.T) {
 
 	events.Send("add", 1, 2, 3)
 	e, _ := events.events.Load("add")
-	runtime.Gosched()
-	assert.Equal(t, int(e.(*event).callTimes), 2)
+	// Close 等待全部调用结束
+	events.Close()
+	assert.Equal(t, int32(2), atomic.LoadInt32(&e.([]*event)[0].callTimes))
 }
 
 func TestEventBus_Remove(t *testing.T) {
@@ -86,9 +105,21 @@ func TestEventBus_Remove(t *testing.T) {
 	assert.NoError(t, err)
 }
 
-func TestPanic(t *testing.T) {
+func TestEventBus_RemovePrefix(t *testing.T) {
 	events := New()
 	defer events.Close()
+	for _, key := range []string{"mod.a", "mod.b", "other"} {
+		assert.NoError(t, events.On(key, func() {}))
+	}
+	events.RemovePrefix("mod.")
+	assert.Equal(t, []string{"other"}, events.Keys())
+	assert.Equal(t, ErrNotFound, events.Send("mod.a"))
+	assert.NoError(t, events.Send("other"))
+}
+
+func TestPanic(t *testing.T) {
+	events := New(WithSilentPanics())
+	defer events.Close()
 	events.Once("panic", makePanic)
 	err := events.Send("panic")
 	assert.NoError(t, err)
@@ -98,3 +129,213 @@ func TestPanic(t *testing.T) {
 	err = events.Send("panic")
 	assert.EqualError(t, err, ErrNotFound.Error())
 }
+
+func TestEventBus_SendWaitSub(t *testing.T) {
+	events := New()
+	defer events.Close()
+
+	done := make(chan int, 1)
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		events.On("late", func(a int) {
+			done <- a
+		})
+	}()
+	err := events.SendWaitSub("late", time.Second, 1)
+	assert.NoError(t, err)
+	select {
+	case a := <-done:
+		assert.Equal(t, 1, a)
+	case <-time.After(time.Second):
+		t.Fatal("event not received")
+	}
+
+	// 超时仍未注册
+	err = events.SendWaitSub("never", 10*time.Millisecond)
+	assert.EqualError(t, err, ErrNotFound.Error())
+}
+
+func TestEventBus_Freeze(t *testing.T) {
+	events := New()
+	defer events.Close()
+	noop := func(a, b, c int) {}
+	events.On("add", noop)
+	events.Once("addOnce", noop)
+	events.Freeze()
+
+	err := events.On("sub", noop)
+	assert.EqualError(t, err, ErrFrozen.Error())
+	err = events.Once("subOnce", noop)
+	assert.EqualError(t, err, ErrFrozen.Error())
+
+	// 冻结后 Remove 无效
+	events.Remove("add")
+	err = events.Send("add", 1, 2, 3)
+	assert.NoError(t, err)
+
+	// once 事件依旧自动注销
+	err = events.Send("addOnce", 1, 2, 3)
+	assert.NoError(t, err)
+	err = events.Send("addOnce", 1, 2, 3)
+	assert.EqualError(t, err, ErrNotFound.Error())
+}
+
+func TestEventBus_ReplaceAll(t *testing.T) {
+	events := New()
+	defer events.Close()
+	noop := func() {}
+	setA := map[string]interface{}{"a1": noop, "a2": noop}
+	setB := map[string]interface{}{"b1": noop, "b2": noop}
+	assert.NoError(t, events.ReplaceAll(setA))
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 1000; i++ {
+			if i%2 == 0 {
+				events.ReplaceAll(setB)
+			} else {
+				events.ReplaceAll(setA)
+			}
+		}
+	}()
+	// 与 Send 相同地在读锁下观察注册表，不应出现新旧混合的状态
+	for running := true; running; {
+		select {
+		case <-done:
+			running = false
+		default:
+		}
+		events.mu.RLock()
+		a1, a2 := len(events.handlers("a1")) > 0, len(events.handlers("a2")) > 0
+		b1, b2 := len(events.handlers("b1")) > 0, len(events.handlers("b2")) > 0
+		events.mu.RUnlock()
+		if a1 != a2 || b1 != b2 || a1 == b1 {
+			t.Fatalf("partial state observed: a1 %t a2 %t b1 %t b2 %t", a1, a2, b1, b2)
+		}
+	}
+
+	// 调用方法不合法时注册表保持不变
+	err := events.ReplaceAll(map[string]interface{}{"c": 1})
+	assert.EqualError(t, err, ErrNotCallable.Error())
+	assert.NoError(t, events.Send("a1"))
+}
+
+func TestEventBus_OnceConcurrent(t *testing.T) {
+	events := New()
+	defer events.Close()
+	assert.NoError(t, events.Once("init", func() {}))
+
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		errs   = map[error]int{}
+		starts = make(chan struct{})
+	)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-starts
+			err := events.Send("init")
+			mu.Lock()
+			errs[err]++
+			mu.Unlock()
+		}()
+	}
+	close(starts)
+	wg.Wait()
+
+	// 只有一个发送成功，其余的发送被告知订阅已经触发或已经移除
+	assert.Equal(t, 1, errs[nil])
+	assert.Equal(t, 19, errs[ErrAlreadyFired]+errs[ErrNotFound])
+}
+
+func TestClaimOnce(t *testing.T) {
+	on, once := &event{}, &event{once: true}
+	handlers := []*event{on, once}
+	claimed, err := claimOnce(handlers)
+	assert.NoError(t, err)
+	assert.Equal(t, handlers, claimed)
+	claimed, err = claimOnce(handlers)
+	assert.NoError(t, err)
+	assert.Equal(t, []*event{on}, claimed)
+	_, err = claimOnce([]*event{once})
+	assert.Equal(t, ErrAlreadyFired, err)
+}
+
+func TestEventBus_SendAfterClose(t *testing.T) {
+	events := New(WithMaxGoroutines(1), WithGoroutineLimitFailFast())
+	assert.NoError(t, events.On("job", func() {}))
+	events.Close()
+	assert.Equal(t, ErrBusClosed, events.Send("job"))
+	assert.Equal(t, ErrBusClosed, events.SendPriority("job", 1))
+	assert.Len(t, events.slots, 0)
+}
+
+func TestEventBus_SendDuringClose(t *testing.T) {
+	for i := 0; i < 10; i++ {
+		events := New()
+		var called int32
+		assert.NoError(t, events.On("add", func() { atomic.AddInt32(&called, 1) }))
+		var (
+			wg   sync.WaitGroup
+			sent int32
+		)
+		for j := 0; j < 4; j++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for k := 0; k < 200; k++ {
+					err := events.Send("add")
+					if err == ErrBusClosed {
+						return
+					}
+					assert.NoError(t, err)
+					atomic.AddInt32(&sent, 1)
+				}
+			}()
+		}
+		time.Sleep(100 * time.Microsecond)
+		events.Close()
+		wg.Wait()
+		// 返回 nil 的发送全部被调用，之后的发送返回 ErrBusClosed 而不是被遗留在队列中
+		assert.Equal(t, atomic.LoadInt32(&sent), atomic.LoadInt32(&called))
+		assert.Empty(t, events.PendingSnapshot())
+	}
+}
+
+func TestEventBus_HotSwap(t *testing.T) {
+	events := New()
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var mu sync.Mutex
+	var got []string
+	record := func(s string) {
+		mu.Lock()
+		got = append(got, s)
+		mu.Unlock()
+	}
+	assert.NoError(t, events.On("job", func(n int) {
+		if n == 1 {
+			close(started)
+			<-release
+		}
+		record(fmt.Sprintf("old-%d", n))
+	}))
+	assert.NoError(t, events.Send("job", 1))
+	<-started
+
+	// 旧订阅仍在执行时替换，之后的发送调用新订阅
+	assert.NoError(t, events.HotSwap("job", func(n int) { record(fmt.Sprintf("new-%d", n)) }))
+	assert.NoError(t, events.SendSync("job", 2))
+	assert.Equal(t, []string{"new-2"}, got)
+	close(release)
+	events.Close()
+	assert.Equal(t, []string{"new-2", "old-1"}, got)
+	assert.Len(t, events.Snapshot(), 1)
+
+	empty := New()
+	defer empty.Close()
+	assert.Equal(t, ErrNotFound, empty.HotSwap("missing", func() {}))
+}
@@ -0,0 +1,88 @@
+package eventbus
+
+import "sync"
+
+// defaultErrorWindow 默认统计每个事件最近多少次执行结果
+const defaultErrorWindow = 100
+
+// outcomes 按事件记录最近的执行结果
+type outcomes struct {
+	mu     sync.Mutex
+	window int
+	keys   map[string]*outcome
+}
+
+// a outcome 一个事件最近 window 次执行结果的滑动窗口
+type outcome struct {
+	failed  []bool
+	next    int
+	errors  int
+	lastErr error
+}
+
+// WithErrorRateWindow 设置 ErrorRate 统计的滑动窗口大小，即每个事件最近 n 次发送，默认 100
+func WithErrorRateWindow(n int) Option {
+	return func(p *EventBus) {
+		p.outcomes.window = n
+	}
+}
+
+// recordOutcome 记录一次发送的执行结果，同时更新 ErrorRate 和熔断器
+func (p *EventBus) recordOutcome(eventKey string, err error) {
+	p.outcomes.record(eventKey, err)
+	p.breaker.record(eventKey, err, p.clock.Now())
+}
+
+// record 记录一次发送的执行结果
+func (o *outcomes) record(eventKey string, err error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.keys == nil {
+		o.keys = make(map[string]*outcome)
+	}
+	r, ok := o.keys[eventKey]
+	if !ok {
+		window := o.window
+		if window <= 0 {
+			window = defaultErrorWindow
+		}
+		r = &outcome{failed: make([]bool, 0, window)}
+		o.keys[eventKey] = r
+	}
+	failed := err != nil
+	if failed {
+		r.errors++
+		r.lastErr = err
+	}
+	if len(r.failed) < cap(r.failed) {
+		r.failed = append(r.failed, failed)
+		return
+	}
+	if r.failed[r.next] {
+		r.errors--
+	}
+	r.failed[r.next] = failed
+	r.next = (r.next + 1) % len(r.failed)
+}
+
+// ErrorRate 返回事件最近若干次发送中执行出错(含 panic)的比例，没有执行记录时返回 0
+// 可以据此实现熔断等策略
+func (p *EventBus) ErrorRate(eventKey string) float64 {
+	p.outcomes.mu.Lock()
+	defer p.outcomes.mu.Unlock()
+	r, ok := p.outcomes.keys[eventKey]
+	if !ok || len(r.failed) == 0 {
+		return 0
+	}
+	return float64(r.errors) / float64(len(r.failed))
+}
+
+// LastError 返回事件最近一次执行出错的错误，从未出错时返回 nil
+func (p *EventBus) LastError(eventKey string) error {
+	p.outcomes.mu.Lock()
+	defer p.outcomes.mu.Unlock()
+	if r, ok := p.outcomes.keys[eventKey]; ok {
+		return r.lastErr
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package eventbus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventBus_ErrorRate(t *testing.T) {
+	events := New()
+	assert.NoError(t, events.On("job", func(fail bool) {
+		if fail {
+			panic("job failed")
+		}
+	}))
+	assert.Equal(t, float64(0), events.ErrorRate("job"))
+	for _, fail := range []bool{true, false, false, true} {
+		assert.NoError(t, events.Send("job", fail))
+	}
+	events.Close()
+
+	assert.Equal(t, 0.5, events.ErrorRate("job"))
+	assert.ErrorIs(t, events.LastError("job"), ErrRuntimePanic)
+	assert.Nil(t, events.LastError("missing"))
+}
+
+func TestOutcomes_record(t *testing.T) {
+	events := New(WithErrorRateWindow(4))
+	defer events.Close()
+	for _, err := range []error{ErrRuntimePanic, nil, nil, ErrNotFound, nil, nil} {
+		events.outcomes.record("a", err)
+	}
+	// 窗口只保留最近 4 次：nil ErrNotFound nil nil
+	assert.Equal(t, 0.25, events.ErrorRate("a"))
+	assert.Equal(t, ErrNotFound, events.LastError("a"))
+
+	var o outcomes
+	o.record("a", nil)
+	assert.Equal(t, defaultErrorWindow, cap(o.keys["a"].failed))
+}
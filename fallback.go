@@ -0,0 +1,22 @@
+package eventbus
+
+// OnFallback 注册兜底订阅，Send 的事件没有任何订阅时调用，能够处理该事件，Send 不再返回 ErrNotFound
+// 调用方法的第一个参数必须是 string，接收实际发送的事件，其余参数接收发送的入参，
+// 声明为 func(eventKey string, args ...interface{}) 即可处理任意入参。重复调用会替换之前的兜底订阅
+func (p *EventBus) OnFallback(call interface{}) error {
+	if p.isFrozen() {
+		return ErrFrozen
+	}
+	e := &event{key: "fallback", call: call, withKey: true}
+	if err := p.setup(e); err != nil {
+		return err
+	}
+	// 兜底订阅可能收到受保护的事件
+	if err := p.authorizeAny(); err != nil {
+		return err
+	}
+	p.mu.Lock()
+	p.fallback = e
+	p.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,70 @@
+package eventbus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventBus_OnFallback(t *testing.T) {
+	events := New()
+	defer events.Close()
+
+	type call struct {
+		key  string
+		args []interface{}
+	}
+	got := make(chan call, 2)
+	err := events.OnFallback(func(key string, args ...interface{}) {
+		got <- call{key, args}
+	})
+	assert.NoError(t, err)
+	events.On("known", func(a int) {})
+
+	err = events.Send("unknown", 1, "a")
+	assert.NoError(t, err)
+	select {
+	case c := <-got:
+		assert.Equal(t, call{"unknown", []interface{}{1, "a"}}, c)
+	case <-time.After(time.Second):
+		t.Fatal("fallback not called")
+	}
+
+	// 已注册的事件不会调用兜底订阅
+	err = events.Send("known", 1)
+	assert.NoError(t, err)
+	select {
+	case c := <-got:
+		t.Fatalf("fallback called for %s", c.key)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	// 第一个参数必须接收事件
+	err = events.OnFallback(func(a int) {})
+	assert.EqualError(t, err, ErrNotCallable.Error())
+
+	// 参数固定的兜底订阅同样校验入参
+	err = events.OnFallback(func(key string, id int) {})
+	assert.NoError(t, err)
+	err = events.Send("unknown")
+	assert.EqualError(t, err, ErrArgsNotMatch.Error())
+}
+
+func TestEvent_Variadic(t *testing.T) {
+	events := New()
+	defer events.Close()
+	got := make(chan []string, 1)
+	events.On("join", func(sep string, parts ...string) {
+		got <- append([]string{sep}, parts...)
+	})
+
+	err := events.Send("join")
+	assert.EqualError(t, err, ErrArgsNotMatch.Error())
+	err = events.Send("join", ",")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{","}, <-got)
+	err = events.Send("join", ",", "a", "b")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{",", "a", "b"}, <-got)
+}
@@ -0,0 +1,63 @@
+package eventbus
+
+// fastCall 注册时按调用方法的签名选择不经过反射的调用，只支持下面固定的几种常见签名，其他签名返回 nil，
+// 始终使用反射调用(反射需要的类型信息同样只在首次调用时生成一次)。发送时不根据入参类型重新生成，
+// 入参类型与签名不符时返回 false，由反射调用处理，保证两条路径的行为一致
+func fastCall(call interface{}) func(args []interface{}) (bool, error) {
+	switch f := call.(type) {
+	case func() error:
+		return func(args []interface{}) (bool, error) {
+			return true, f()
+		}
+	case func(interface{}):
+		return func(args []interface{}) (bool, error) {
+			f(args[0])
+			return true, nil
+		}
+	case func(interface{}) error:
+		return func(args []interface{}) (bool, error) {
+			return true, f(args[0])
+		}
+	case func(string):
+		return func(args []interface{}) (bool, error) {
+			s, ok := args[0].(string)
+			if ok {
+				f(s)
+			}
+			return ok, nil
+		}
+	case func(string) error:
+		return func(args []interface{}) (bool, error) {
+			s, ok := args[0].(string)
+			if !ok {
+				return false, nil
+			}
+			return true, f(s)
+		}
+	case func(int):
+		return func(args []interface{}) (bool, error) {
+			n, ok := args[0].(int)
+			if ok {
+				f(n)
+			}
+			return ok, nil
+		}
+	case func(int) error:
+		return func(args []interface{}) (bool, error) {
+			n, ok := args[0].(int)
+			if !ok {
+				return false, nil
+			}
+			return true, f(n)
+		}
+	case func(string, interface{}):
+		return func(args []interface{}) (bool, error) {
+			s, ok := args[0].(string)
+			if ok {
+				f(s, args[1])
+			}
+			return ok, nil
+		}
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFastCall(t *testing.T) {
+	failed := errors.New("failed")
+	var got []interface{}
+	tests := []struct {
+		name string
+		call interface{}
+		args []interface{}
+		err  error
+		want []interface{}
+	}{
+		{"func()", func() { got = append(got, "called") }, nil, nil, []interface{}{"called"}},
+		{"func() error", func() error { return failed }, nil, failed, nil},
+		{"func(interface{})", func(v interface{}) { got = append(got, v) }, []interface{}{1.5}, nil, []interface{}{1.5}},
+		{"func(interface{}) nil", func(v interface{}) { got = append(got, v) }, []interface{}{nil}, nil, []interface{}{nil}},
+		{"func(string)", func(s string) { got = append(got, s) }, []interface{}{"a"}, nil, []interface{}{"a"}},
+		{"func(string) nil", func(s string) { got = append(got, s) }, []interface{}{nil}, nil, []interface{}{""}},
+		{"func(string) error", func(s string) error { return failed }, []interface{}{"a"}, failed, nil},
+		{"func(int)", func(n int) { got = append(got, n) }, []interface{}{1}, nil, []interface{}{1}},
+		{"func(int) error", func(n int) error { got = append(got, n); return nil }, []interface{}{2}, nil, []interface{}{2}},
+		{"func(int) wrong type", func(n int) { got = append(got, n) }, []interface{}{"x"}, ErrRuntimePanic, nil},
+		{"func(string, interface{})", func(s string, v interface{}) { got = append(got, s, v) }, []interface{}{"k", 3}, nil, []interface{}{"k", 3}},
+		{"reflect", func(a, b int) { got = append(got, a+b) }, []interface{}{1, 2}, nil, []interface{}{3}},
+	}
+	events := New()
+	defer events.Close()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got = nil
+			e := &event{key: tt.name, call: tt.call}
+			assert.NoError(t, events.setup(e))
+			assert.ErrorIs(t, e.Call(tt.args), tt.err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func BenchmarkEvent_CallFast(b *testing.B) {
+	events := New()
+	defer events.Close()
+	events.On("row", func(n int) error { return nil })
+	e := events.handlers("row")[0]
+	args := []interface{}{1}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		e.Call(args)
+	}
+}
+
+func TestEvent_CallSignal(t *testing.T) {
+	events := New()
+	defer events.Close()
+	n := 0
+	assert.NoError(t, events.On("tick", func() { n++ }))
+	e := events.handlers("tick")[0]
+	assert.NotNil(t, e.signal)
+	assert.NoError(t, e.Call(nil))
+	assert.NoError(t, e.Call([]interface{}{"ignored"}))
+	assert.Equal(t, 2, n)
+
+	// 有返回值或需要注入的订阅不走信号路径
+	assert.NoError(t, events.On("tock", func() error { return nil }))
+	assert.Nil(t, events.handlers("tock")[0].signal)
+	assert.NoError(t, events.On("ctx", func(ctx context.Context) {}))
+	assert.Nil(t, events.handlers("ctx")[0].signal)
+}
+
+func BenchmarkEvent_CallSignal(b *testing.B) {
+	events := New()
+	defer events.Close()
+	events.On("tick", func() {})
+	e := events.handlers("tick")[0]
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		e.Call(nil)
+	}
+}
+
+func BenchmarkEvent_CallSlow(b *testing.B) {
+	events := New()
+	defer events.Close()
+	events.On("row", func(n int8) error { return nil })
+	e := events.handlers("row")[0]
+	args := []interface{}{int8(1)}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		e.Call(args)
+	}
+}
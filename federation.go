@@ -0,0 +1,63 @@
+package eventbus
+
+import (
+	"strings"
+	"sync"
+)
+
+// Federation 在多个订阅器之间按事件前缀转发发送，用于把大型系统拆分为多个领域订阅器
+// 使用同一个 Federation 的订阅器发送匹配前缀的事件时，转发到路由指定的订阅器
+type Federation struct {
+	mu     sync.RWMutex
+	routes []route
+}
+
+type route struct {
+	prefix string
+	target *EventBus
+}
+
+// NewFederation 构建一个没有路由的 Federation
+func NewFederation() *Federation {
+	return &Federation{}
+}
+
+// WithFederation 发送时使用 f 的路由，匹配的事件转发到其他订阅器
+func WithFederation(f *Federation) Option {
+	return func(p *EventBus) {
+		p.federation = f
+	}
+}
+
+// Route 以 prefix 开头的事件转发到 target，重复设置同一前缀会覆盖之前的路由
+// 多个前缀都匹配时使用最长的前缀
+func (f *Federation) Route(prefix string, target *EventBus) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i, r := range f.routes {
+		if r.prefix == prefix {
+			f.routes[i].target = target
+			return
+		}
+	}
+	f.routes = append(f.routes, route{prefix: prefix, target: target})
+}
+
+// target 返回事件应该转发到的订阅器，没有匹配的路由时返回 nil
+func (f *Federation) target(eventKey string) *EventBus {
+	if f == nil {
+		return nil
+	}
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	var best *route
+	for i, r := range f.routes {
+		if strings.HasPrefix(eventKey, r.prefix) && (best == nil || len(r.prefix) > len(best.prefix)) {
+			best = &f.routes[i]
+		}
+	}
+	if best == nil {
+		return nil
+	}
+	return best.target
+}
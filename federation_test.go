@@ -0,0 +1,43 @@
+package eventbus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFederation_Route(t *testing.T) {
+	f := NewFederation()
+	orders := New(WithFederation(f))
+	users := New(WithFederation(f))
+	f.Route("order.", orders)
+	f.Route("user.", users)
+
+	got := make(chan string, 3)
+	assert.NoError(t, orders.On("order.paid", func(id string) { got <- "orders:" + id }))
+	assert.NoError(t, users.On("user.login", func(id string) { got <- "users:" + id }))
+	assert.NoError(t, users.On("local", func(id string) { got <- "local:" + id }))
+
+	// 在任一订阅器发送都转发到前缀对应的订阅器
+	assert.NoError(t, users.Send("order.paid", "1"))
+	assert.NoError(t, orders.Send("user.login", "2"))
+	assert.NoError(t, users.Send("local", "3"))
+	// 没有路由的事件在本地查找
+	assert.EqualError(t, orders.Send("local", "4"), ErrNotFound.Error())
+	orders.Close()
+	users.Close()
+
+	assert.ElementsMatch(t, []string{"orders:1", "users:2", "local:3"}, []string{<-got, <-got, <-got})
+}
+
+func TestFederation_target(t *testing.T) {
+	a, b := &EventBus{}, &EventBus{}
+	f := NewFederation()
+	f.Route("remote.", a)
+	f.Route("remote.b.", b)
+	assert.Equal(t, a, f.target("remote.a.x"))
+	assert.Equal(t, b, f.target("remote.b.x"))
+	assert.Nil(t, f.target("local"))
+	f.Route("remote.", b)
+	assert.Equal(t, b, f.target("remote.a.x"))
+}
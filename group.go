@@ -0,0 +1,79 @@
+package eventbus
+
+import "sync/atomic"
+
+// OnGroup 以消费组的方式注册订阅，同一组内的订阅轮流处理发送，每次发送只调用组内一个订阅，
+// 不同的组各自收到一份。同一事件允许注册多个订阅
+func (p *EventBus) OnGroup(eventKey, group string, call interface{}) error {
+	return p.on(&event{key: eventKey, call: call, group: group}, false)
+}
+
+// groupPick 消费组选出订阅的方式
+type groupPick int
+
+const (
+	// pickNext 轮询选出一个订阅并推进计数，用于真正的调用
+	pickNext groupPick = iota
+	// pickPeek 选出下一次调用将轮到的订阅，不推进计数，用于只读的查询
+	pickPeek
+	// pickAll 保留组内全部订阅
+	pickAll
+)
+
+// balance 每个消费组只保留一个轮询选出的订阅，结果保持注册顺序，没有消费组时返回原切片
+func (p *EventBus) balance(eventKey string, handlers []*event, pick groupPick) []*event {
+	if pick == pickAll {
+		return handlers
+	}
+	var members map[string][]*event
+	for _, e := range handlers {
+		if e.group != "" {
+			if members == nil {
+				members = map[string][]*event{}
+			}
+			members[e.group] = append(members[e.group], e)
+		}
+	}
+	if members == nil {
+		return handlers
+	}
+	selected := make([]*event, 0, len(handlers))
+	for _, e := range handlers {
+		if e.group == "" {
+			selected = append(selected, e)
+			continue
+		}
+		group, ok := members[e.group]
+		if !ok {
+			// 该组已经选出
+			continue
+		}
+		delete(members, e.group)
+		var n uint64
+		if pick == pickPeek {
+			n = p.peekCursor(eventKey, e.group) + 1
+		} else {
+			n = atomic.AddUint64(p.cursor(eventKey, e.group), 1)
+		}
+		selected = append(selected, group[(n-1)%uint64(len(group))])
+	}
+	return selected
+}
+
+// cursor 返回消费组的轮询计数
+func (p *EventBus) cursor(eventKey, group string) *uint64 {
+	key := eventKey + "\x00" + group
+	if c, ok := p.cursors.Load(key); ok {
+		return c.(*uint64)
+	}
+	c, _ := p.cursors.LoadOrStore(key, new(uint64))
+	return c.(*uint64)
+}
+
+// peekCursor 返回消费组当前的轮询计数，不创建计数
+func (p *EventBus) peekCursor(eventKey, group string) uint64 {
+	if c, ok := p.cursors.Load(eventKey + "\x00" + group); ok {
+		return atomic.LoadUint64(c.(*uint64))
+	}
+	return 0
+}
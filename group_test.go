@@ -0,0 +1,36 @@
+package eventbus
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventBus_OnGroup(t *testing.T) {
+	events := New()
+	var (
+		mu     sync.Mutex
+		counts = map[string]int{}
+	)
+	handler := func(name string) func(int) {
+		return func(int) {
+			mu.Lock()
+			counts[name]++
+			mu.Unlock()
+		}
+	}
+	assert.NoError(t, events.OnGroup("order", "billing", handler("billing-1")))
+	assert.NoError(t, events.OnGroup("order", "billing", handler("billing-2")))
+	assert.NoError(t, events.OnGroup("order", "audit", handler("audit-1")))
+	_, err := events.Subscribe("order", handler("plain"))
+	assert.NoError(t, err)
+
+	for i := 0; i < 10; i++ {
+		assert.NoError(t, events.Send("order", i))
+	}
+	events.Close()
+
+	// 每组都收到全部发送，组内负载均衡
+	assert.Equal(t, map[string]int{"billing-1": 5, "billing-2": 5, "audit-1": 10, "plain": 10}, counts)
+}
@@ -0,0 +1,86 @@
+package eventbus
+
+import (
+	"reflect"
+	"strings"
+)
+
+// WithHistoryArgLimit 限制 WithHistory 记录的每个入参大小，超过 bytes 的字符串和切片在历史中截断，
+// 避免长期保留的历史占用大块内存。只影响历史中的副本，实时调用仍然收到完整的入参
+func WithHistoryArgLimit(bytes int) Option {
+	return func(p *EventBus) {
+		p.historyArgLimit = bytes
+	}
+}
+
+// truncateArgs 返回截断后的入参，没有需要截断的入参时返回 args 本身
+func truncateArgs(args []interface{}, limit int) []interface{} {
+	if limit <= 0 {
+		return args
+	}
+	var out []interface{}
+	for i, arg := range args {
+		cut, ok := truncateArg(arg, limit)
+		if !ok {
+			continue
+		}
+		if out == nil {
+			out = append([]interface{}{}, args...)
+		}
+		out[i] = cut
+	}
+	if out == nil {
+		return args
+	}
+	return out
+}
+
+// truncateArg 截断超过 limit 字节的字符串和切片，截断的部分复制一份，不引用原入参的内存
+// 切片按元素占用的内存计算保留的个数，字符串、切片和指针元素包括它们引用的数据
+func truncateArg(arg interface{}, limit int) (interface{}, bool) {
+	switch v := arg.(type) {
+	case string:
+		if len(v) > limit {
+			return strings.Clone(v[:limit]), true
+		}
+		return nil, false
+	case []byte:
+		if len(v) > limit {
+			return append([]byte(nil), v[:limit]...), true
+		}
+		return nil, false
+	}
+	rv := reflect.ValueOf(arg)
+	if rv.Kind() != reflect.Slice {
+		return nil, false
+	}
+	n, total := 0, 0
+	for ; n < rv.Len(); n++ {
+		if total += elemSize(rv.Index(n)); total > limit {
+			break
+		}
+	}
+	if n == rv.Len() {
+		return nil, false
+	}
+	// 复制保留的部分，不引用原切片的底层数组
+	cut := reflect.MakeSlice(rv.Type(), n, n)
+	reflect.Copy(cut, rv)
+	return cut.Interface(), true
+}
+
+// elemSize 估算切片元素占用的内存，包括字符串、切片和指针引用的数据
+func elemSize(v reflect.Value) int {
+	size := int(v.Type().Size())
+	switch v.Kind() {
+	case reflect.String:
+		size += v.Len()
+	case reflect.Slice:
+		size += v.Len() * int(v.Type().Elem().Size())
+	case reflect.Ptr:
+		if !v.IsNil() {
+			size += int(v.Type().Elem().Size())
+		}
+	}
+	return size
+}
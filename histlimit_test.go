@@ -0,0 +1,59 @@
+package eventbus
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventBus_WithHistoryArgLimit(t *testing.T) {
+	events := New(WithHistory(2), WithHistoryArgLimit(8))
+	defer events.Close()
+	got := make(chan string, 1)
+	assert.NoError(t, events.On("upload", func(name string, data []byte, ids []int64, n int) {
+		got <- name
+	}))
+	name := strings.Repeat("a", 64)
+	assert.NoError(t, events.Send("upload", name, make([]byte, 64), make([]int64, 4), 1))
+	// 实时调用收到完整的入参
+	assert.Equal(t, name, <-got)
+
+	history := events.history.last("upload", 1)
+	if assert.Len(t, history, 1) {
+		args := history[0]
+		assert.Equal(t, "aaaaaaaa", args[0])
+		assert.Len(t, args[1], 8)
+		assert.Len(t, args[2], 1)
+		assert.Equal(t, 1, args[3])
+	}
+}
+
+func TestTruncateArg_References(t *testing.T) {
+	type row struct{ data [64]byte }
+	// 引用数据的元素按引用的数据计算大小
+	cut, ok := truncateArg([]string{strings.Repeat("a", 10), strings.Repeat("b", 10), "c"}, 60)
+	assert.True(t, ok)
+	assert.Equal(t, []string{strings.Repeat("a", 10), strings.Repeat("b", 10)}, cut)
+	cut, ok = truncateArg([]*row{{}, {}}, 100)
+	assert.True(t, ok)
+	assert.Len(t, cut, 1)
+	_, ok = truncateArg([]*row{nil, nil}, 100)
+	assert.False(t, ok)
+}
+
+func TestEventBus_WithHistoryArgLimitReleases(t *testing.T) {
+	events := New(WithHistory(1), WithHistoryArgLimit(8))
+	defer events.Close()
+	assert.NoError(t, events.On("upload", func(name string) {}))
+	const size = 64 << 20
+	assert.NoError(t, events.SendSync("upload", strings.Repeat("a", size)))
+
+	// 历史只保留截断后的副本，原始的大字符串可以被回收
+	var stats runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&stats)
+	assert.Less(t, stats.HeapAlloc, uint64(size/2))
+	assert.Equal(t, "aaaaaaaa", events.history.last("upload", 1)[0][0])
+}
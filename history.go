@@ -0,0 +1,119 @@
+package eventbus
+
+import "sync"
+
+// a history 按事件保存最近 size 次发送的入参
+type history struct {
+	mu   sync.Mutex
+	size int
+	keys map[string]*ring
+}
+
+// a ring 固定容量的环形缓冲，写满后覆盖最早的记录
+type ring struct {
+	items [][]interface{}
+	start int
+}
+
+// snapshot 按发送顺序返回全部事件的历史
+func (h *history) snapshot() map[string][][]interface{} {
+	if h == nil {
+		return nil
+	}
+	h.mu.Lock()
+	keys := make([]string, 0, len(h.keys))
+	for key := range h.keys {
+		keys = append(keys, key)
+	}
+	h.mu.Unlock()
+	all := make(map[string][][]interface{}, len(keys))
+	for _, key := range keys {
+		all[key] = h.last(key, h.size)
+	}
+	return all
+}
+
+// restore 使用 all 替换全部事件的历史，超出 size 的部分只保留最近的记录
+func (h *history) restore(all map[string][][]interface{}) {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	h.keys = make(map[string]*ring, len(all))
+	h.mu.Unlock()
+	for key, items := range all {
+		for _, args := range items {
+			h.push(key, args)
+		}
+	}
+}
+
+// WithHistory 为每个事件保存最近 size 次发送的入参，事件尚未注册时的发送也会被记录
+// OnWithReplayN 从这里读取需要回放的发送
+func WithHistory(size int) Option {
+	return func(p *EventBus) {
+		if size > 0 {
+			p.history = &history{size: size, keys: make(map[string]*ring)}
+		}
+	}
+}
+
+func (h *history) push(eventKey string, args []interface{}) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	r, ok := h.keys[eventKey]
+	if !ok {
+		r = &ring{items: make([][]interface{}, 0, h.size)}
+		h.keys[eventKey] = r
+	}
+	if len(r.items) < h.size {
+		r.items = append(r.items, args)
+		return
+	}
+	r.items[r.start] = args
+	r.start = (r.start + 1) % h.size
+}
+
+// last 按发送顺序返回事件最近 n 次发送的入参
+func (h *history) last(eventKey string, n int) [][]interface{} {
+	if h == nil || n <= 0 {
+		return nil
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	r, ok := h.keys[eventKey]
+	if !ok {
+		return nil
+	}
+	all := append(append([][]interface{}{}, r.items[r.start:]...), r.items[:r.start]...)
+	if n < len(all) {
+		all = all[len(all)-n:]
+	}
+	return all
+}
+
+// OnWithReplayN 注册订阅，注册时先按顺序回放事件最近 n 次的发送，回放结束之后再接收实时发送
+// 需要通过 WithHistory 开启历史记录，入参个数不匹配的历史发送被跳过。同一事件允许注册多个订阅
+// n 小于 0 时按 0 处理，不回放
+func (p *EventBus) OnWithReplayN(eventKey string, n int, call interface{}) error {
+	if n < 0 {
+		n = 0
+	}
+	return p.on(&event{key: eventKey, call: call, replayN: n, ready: make(chan struct{})}, false)
+}
+
+// replay 在后台依次回放历史发送，结束后放行实时发送
+func (p *EventBus) replay(e *event, history [][]interface{}) {
+	p.running.Add(1)
+	go func() {
+		defer p.running.Done()
+		defer close(e.ready)
+		for _, args := range history {
+			if !e.accept(args, p.ignoreExtraArgs) {
+				continue
+			}
+			s := &sender{key: e.key, events: []*event{e}, args: args, replay: true}
+			s.Call()
+		}
+	}()
+}
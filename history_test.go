@@ -0,0 +1,51 @@
+package eventbus
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventBus_OnWithReplayN(t *testing.T) {
+	events := New(WithHistory(3))
+	for i := 1; i <= 5; i++ {
+		assert.EqualError(t, events.Send("tick", i), ErrNotFound.Error())
+	}
+	var (
+		mu       sync.Mutex
+		received []int
+	)
+	assert.NoError(t, events.OnWithReplayN("tick", 2, func(i int) {
+		mu.Lock()
+		received = append(received, i)
+		mu.Unlock()
+	}))
+	assert.NoError(t, events.Send("tick", 6))
+	events.Close()
+
+	// 回放的发送按顺序且先于实时发送
+	assert.Equal(t, []int{4, 5, 6}, received)
+}
+
+func TestHistory_last(t *testing.T) {
+	h := &history{size: 3, keys: make(map[string]*ring)}
+	assert.Nil(t, h.last("a", 3))
+	for i := 1; i <= 4; i++ {
+		h.push("a", []interface{}{i})
+	}
+	assert.Equal(t, [][]interface{}{{2}, {3}, {4}}, h.last("a", 10))
+	assert.Equal(t, [][]interface{}{{4}}, h.last("a", 1))
+}
+
+func TestEventBus_OnWithReplayNNegative(t *testing.T) {
+	events := New(WithHistory(3))
+	// 尚未注册的发送依旧记录历史
+	assert.Equal(t, ErrNotFound, events.Send("a", 1))
+	got := make(chan int, 1)
+	assert.NoError(t, events.OnWithReplayN("a", -1, func(v int) { got <- v }))
+	assert.Nil(t, events.history.last("a", -1))
+	assert.NoError(t, events.Send("a", 2))
+	events.Close()
+	assert.Equal(t, 2, <-got)
+}
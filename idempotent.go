@@ -0,0 +1,57 @@
+package eventbus
+
+import "sync"
+
+// IdempotencyStore 保存已经处理过的幂等键，持久化的实现可以在重启之后继续识别重复发送
+type IdempotencyStore interface {
+	// Claim 登记幂等键，已经登记过时返回 false
+	Claim(key string) (bool, error)
+	// Release 撤销登记，发送失败时调用，重试不会被跳过
+	Release(key string) error
+}
+
+// WithIdempotencyStore 使用 store 保存 SendIdempotent 的幂等键，默认保存在内存中，重启后丢失
+func WithIdempotencyStore(store IdempotencyStore) Option {
+	return func(p *EventBus) {
+		p.idempotency = store
+	}
+}
+
+// memoryIdempotency 默认的内存幂等键存储
+type memoryIdempotency struct {
+	mu   sync.Mutex
+	keys map[string]struct{}
+}
+
+func (m *memoryIdempotency) Claim(key string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.keys[key]; ok {
+		return false, nil
+	}
+	m.keys[key] = struct{}{}
+	return true, nil
+}
+
+func (m *memoryIdempotency) Release(key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.keys, key)
+	return nil
+}
+
+// SendIdempotent 使用幂等键调用事件，同一幂等键只发送一次，之后的重复发送直接返回 nil
+// 发送失败时撤销登记，可以使用同一幂等键重试
+func (p *EventBus) SendIdempotent(idempotencyKey, eventKey string, args ...interface{}) error {
+	fresh, err := p.idempotency.Claim(idempotencyKey)
+	if err != nil || !fresh {
+		return err
+	}
+	if err := p.Send(eventKey, args...); err != nil {
+		if rerr := p.idempotency.Release(idempotencyKey); rerr != nil {
+			p.logger.Printf("[IDEMPOTENCY] release %s: %s", idempotencyKey, rerr)
+		}
+		return err
+	}
+	return nil
+}
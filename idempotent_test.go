@@ -0,0 +1,64 @@
+package eventbus
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeStore 模拟持久化的幂等键存储，多个订阅器共享同一份数据
+type fakeStore struct {
+	mu   sync.Mutex
+	keys map[string]bool
+}
+
+func (s *fakeStore) Claim(key string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.keys[key] {
+		return false, nil
+	}
+	s.keys[key] = true
+	return true, nil
+}
+
+func (s *fakeStore) Release(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.keys, key)
+	return nil
+}
+
+func TestEventBus_SendIdempotent(t *testing.T) {
+	store := &fakeStore{keys: make(map[string]bool)}
+	var calls int32
+	newBus := func() *EventBus {
+		events := New(WithIdempotencyStore(store))
+		assert.NoError(t, events.On("pay", func(id int) { calls++ }))
+		return events
+	}
+	events := newBus()
+	assert.NoError(t, events.SendIdempotent("order-1", "pay", 1))
+	assert.NoError(t, events.SendIdempotent("order-1", "pay", 1))
+	events.Close()
+	assert.Equal(t, int32(1), calls)
+
+	// 重启之后同一幂等键仍然被跳过
+	restarted := newBus()
+	assert.NoError(t, restarted.SendIdempotent("order-1", "pay", 1))
+	assert.NoError(t, restarted.SendIdempotent("order-2", "pay", 2))
+	restarted.Close()
+	assert.Equal(t, int32(2), calls)
+}
+
+func TestEventBus_SendIdempotentRetry(t *testing.T) {
+	events := New()
+	defer events.Close()
+	// 发送失败时撤销登记
+	assert.Equal(t, ErrNotFound, events.SendIdempotent("k", "missing"))
+	done := make(chan struct{})
+	assert.NoError(t, events.On("missing", func() { close(done) }))
+	assert.NoError(t, events.SendIdempotent("k", "missing"))
+	<-done
+}
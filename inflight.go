@@ -0,0 +1,111 @@
+package eventbus
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DispatchInfo 正在执行的发送
+type DispatchInfo struct {
+	ID        uint64
+	Key       string
+	StartedAt time.Time
+	// Context 订阅注入的 context 由它派生，CancelDispatch 后被取消
+	Context context.Context
+}
+
+// a running 正在执行的一次发送
+type running struct {
+	info   DispatchInfo
+	cancel context.CancelFunc
+}
+
+// inflight 按发送ID记录正在执行的发送
+type inflight struct {
+	mu   sync.Mutex
+	runs map[uint64]*running
+}
+
+// begin 登记开始执行的 s，并为它创建可以取消的 context，返回的函数在执行结束后调用并取消 context
+// 订阅中用注入的 context 继续异步发送时，新的发送通过 detach 只继承发送方 context 的取消
+func (p *EventBus) begin(s *sender) func() {
+	parent := s.ctx
+	if parent == nil {
+		parent = context.Background()
+	}
+	ctx, cancel := context.WithCancel(context.WithValue(parent, runKey{}, runOrigin{ctx: s.ctx}))
+	s.runCtx = ctx
+	r := &running{info: DispatchInfo{ID: s.id, Key: s.key, StartedAt: p.clock.Now(), Context: ctx}, cancel: cancel}
+	p.inflight.mu.Lock()
+	if p.inflight.runs == nil {
+		p.inflight.runs = make(map[uint64]*running)
+	}
+	p.inflight.runs[s.id] = r
+	p.inflight.mu.Unlock()
+	return func() {
+		p.inflight.mu.Lock()
+		delete(p.inflight.runs, s.id)
+		p.inflight.mu.Unlock()
+		cancel()
+	}
+}
+
+type runKey struct{}
+
+// runOrigin 执行期间的 context 对应的发送方 context，发送方没有传入时为空
+type runOrigin struct {
+	ctx context.Context
+}
+
+// detachedCtx 保留一次执行中 context 的值，取消只跟随发送方的 context
+type detachedCtx struct {
+	context.Context
+	origin context.Context
+}
+
+func (c detachedCtx) Deadline() (time.Time, bool) { return c.origin.Deadline() }
+func (c detachedCtx) Done() <-chan struct{}       { return c.origin.Done() }
+func (c detachedCtx) Err() error                  { return c.origin.Err() }
+
+// detach 让 ctx 不再随所属执行的结束或 CancelDispatch 取消，ctx 不属于任何执行时原样返回
+// 用于订阅中继续异步发送和批量订阅暂存的发送，它们在触发它们的执行结束之后才会调用
+func detach(ctx context.Context) context.Context {
+	if ctx == nil {
+		return nil
+	}
+	run, ok := ctx.Value(runKey{}).(runOrigin)
+	if !ok {
+		return ctx
+	}
+	origin := run.ctx
+	if origin == nil {
+		origin = context.Background()
+	}
+	return detachedCtx{Context: ctx, origin: origin}
+}
+
+// InFlightDispatches 返回正在执行的发送，按发送ID排列，用于排查卡住的订阅
+func (p *EventBus) InFlightDispatches() []DispatchInfo {
+	p.inflight.mu.Lock()
+	infos := make([]DispatchInfo, 0, len(p.inflight.runs))
+	for _, r := range p.inflight.runs {
+		infos = append(infos, r.info)
+	}
+	p.inflight.mu.Unlock()
+	sort.Slice(infos, func(i, j int) bool { return infos[i].ID < infos[j].ID })
+	return infos
+}
+
+// CancelDispatch 取消正在执行的发送 id 的 context，接收 context 的订阅可以据此提前结束
+// 发送已经执行结束或不存在时返回 false。不接收 context 的订阅不受影响
+func (p *EventBus) CancelDispatch(id uint64) bool {
+	p.inflight.mu.Lock()
+	r, ok := p.inflight.runs[id]
+	p.inflight.mu.Unlock()
+	if ok {
+		r.cancel()
+	}
+	return ok
+}
@@ -0,0 +1,61 @@
+package eventbus
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventBus_CancelDispatch(t *testing.T) {
+	events := New()
+	defer events.Close()
+	started := make(chan struct{}, 2)
+	finished := make(chan string, 2)
+	assert.NoError(t, events.On("slow", func(ctx context.Context, name string) {
+		started <- struct{}{}
+		select {
+		case <-ctx.Done():
+			finished <- name + " canceled"
+		case <-time.After(200 * time.Millisecond):
+			finished <- name + " done"
+		}
+	}))
+	assert.NoError(t, events.Send("slow", "a"))
+	assert.NoError(t, events.Send("slow", "b"))
+	<-started
+	<-started
+
+	running := events.InFlightDispatches()
+	if assert.Len(t, running, 2) {
+		assert.Equal(t, "slow", running[0].Key)
+		assert.True(t, running[0].ID < running[1].ID)
+		assert.NoError(t, running[0].Context.Err())
+	}
+	assert.True(t, events.CancelDispatch(running[0].ID))
+	assert.Equal(t, context.Canceled, running[0].Context.Err())
+	first := <-finished
+	assert.Contains(t, first, "canceled")
+	assert.Contains(t, <-finished, "done")
+	events.Close()
+	assert.Empty(t, events.InFlightDispatches())
+	assert.False(t, events.CancelDispatch(running[1].ID))
+}
+
+func TestEventBus_DispatchContextReleased(t *testing.T) {
+	events := New()
+	defer events.Close()
+	got := make(chan context.Context, 1)
+	assert.NoError(t, events.On("job", func(ctx context.Context) { got <- ctx }))
+	parent, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	assert.NoError(t, events.SendSyncCtx(parent, "job"))
+	// 执行结束后取消，不会在长期存在的父 context 上遗留子 context
+	assert.Equal(t, context.Canceled, (<-got).Err())
+	assert.NoError(t, parent.Err())
+
+	assert.NoError(t, events.SendCtx(parent, "job"))
+	ctx := <-got
+	assert.Eventually(t, func() bool { return ctx.Err() != nil }, time.Second, time.Millisecond)
+}
@@ -0,0 +1,99 @@
+package eventbus
+
+import (
+	"context"
+	"reflect"
+)
+
+// injectKind 订阅前置参数的注入类型
+type injectKind int
+
+const (
+	// injectPropagation 注入 *Propagation
+	injectPropagation injectKind = iota
+	// injectKey 注入实际发送的事件，只用于兜底等需要区分事件的订阅
+	injectKey
+	// injectContext 注入本次调用的 context.Context
+	injectContext
+	// injectBus 注入订阅所在的 *EventBus 或 Emitter
+	injectBus
+)
+
+var (
+	propagationType = reflect.TypeOf((*Propagation)(nil))
+	contextType     = reflect.TypeOf((*context.Context)(nil)).Elem()
+	stringType      = reflect.TypeOf("")
+	busType         = reflect.TypeOf((*EventBus)(nil))
+	emitterType     = reflect.TypeOf((*Emitter)(nil)).Elem()
+)
+
+// injectsOf 从第 start 个参数开始识别可以由订阅器注入的参数
+func injectsOf(t reflect.Type, start int) []injectKind {
+	var injects []injectKind
+	for i := start; i < t.NumIn(); i++ {
+		switch t.In(i) {
+		case propagationType:
+			injects = append(injects, injectPropagation)
+		case contextType:
+			injects = append(injects, injectContext)
+		case busType, emitterType:
+			injects = append(injects, injectBus)
+		default:
+			return injects
+		}
+	}
+	return injects
+}
+
+// a dispatch 一次发送的调用上下文，同一次发送的全部订阅共享
+type dispatch struct {
+	id       uint64
+	key      string
+	priority int
+	prop     *Propagation
+	parent   context.Context
+	ctx      context.Context
+	// sync 同步发送的状态，异步发送为空
+	sync *syncState
+	// ack SendAck 等待的确认，其他发送为空
+	ack *ackState
+}
+
+// inject 返回需要注入的参数值
+func (d *dispatch) inject(kind injectKind) reflect.Value {
+	switch kind {
+	case injectPropagation:
+		if d.prop == nil {
+			d.prop = &Propagation{}
+		}
+		return reflect.ValueOf(d.prop)
+	case injectKey:
+		return reflect.ValueOf(d.key)
+	case injectContext:
+		return reflect.ValueOf(d.context())
+	}
+	return reflect.Value{}
+}
+
+// context 返回传给订阅的 context，在发送方 context 的基础上记录因果链，首次使用时构建
+func (d *dispatch) context() context.Context {
+	if d.ctx == nil {
+		parent := d.parent
+		if parent == nil {
+			parent = context.Background()
+		}
+		chain := CausationChain(parent)
+		chain = append(chain[:len(chain):len(chain)], Cause{ID: d.id, Key: d.key, Priority: d.priority})
+		ctx := context.WithValue(parent, causationKey{}, chain)
+		if d.sync != nil || syncStateOf(parent) != nil {
+			// 异步发送的 context 不继承同步发送的状态
+			ctx = context.WithValue(ctx, syncKey{}, d.sync)
+		}
+		if d.ack != nil || ackOf(parent) != nil {
+			// 后续发送的订阅不能确认触发它的 SendAck
+			ctx = context.WithValue(ctx, ackKey{}, d.ack)
+		}
+		d.ctx = ctx
+	}
+	return d.ctx
+}
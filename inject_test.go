@@ -0,0 +1,42 @@
+package eventbus
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInjectBus(t *testing.T) {
+	events := New()
+	got := make(chan string, 2)
+	// 订阅不需要在闭包中引用订阅器即可继续发送
+	assert.NoError(t, events.On("order.created", func(bus *EventBus, id string) {
+		assert.NoError(t, bus.Send("order.notify", id))
+	}))
+	assert.NoError(t, events.On("order.notify", func(emitter Emitter, id string) {
+		got <- "notify:" + id
+	}))
+
+	assert.NoError(t, events.Send("order.created", "1"))
+	assert.Equal(t, "notify:1", <-got)
+	events.Close()
+
+	// 注入的参数不计入入参个数
+	assert.Equal(t, 1, events.handlers("order.created")[0].argsNums)
+}
+
+func TestInjectContextArgsNums(t *testing.T) {
+	events := New()
+	defer events.Close()
+	got := make(chan int, 1)
+	assert.NoError(t, events.On("add", func(ctx context.Context, a int) { got <- a }))
+	// 注入的 context 不计入入参个数，发送方只需要传入业务参数
+	assert.NoError(t, events.Send("add", 1))
+	assert.Equal(t, 1, <-got)
+	assert.Equal(t, ErrArgsNotMatch, events.SendSync("add"))
+	handlers := events.handlers("add")
+	if assert.Len(t, handlers, 1) {
+		assert.Equal(t, 1, handlers[0].argsNums)
+	}
+}
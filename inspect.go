@@ -0,0 +1,108 @@
+package eventbus
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// EventInfo 一个订阅的注册信息
+type EventInfo struct {
+	Key       string
+	ID        uint64
+	Once      bool
+	Version   int
+	CallTimes int32
+	// Meta OnWithMeta 附加的元数据，不可修改
+	Meta map[string]interface{}
+	// Registered 注册时间
+	Registered time.Time
+}
+
+// Keys 返回已注册的全部事件，按字典序排列
+func (p *EventBus) Keys() []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	var keys []string
+	p.events.Range(func(key, _ interface{}) bool {
+		keys = append(keys, key.(string))
+		return true
+	})
+	sort.Strings(keys)
+	return keys
+}
+
+// Snapshot 返回全部订阅的注册信息，按事件字典序排列，同一事件按注册顺序排列
+func (p *EventBus) Snapshot() []EventInfo {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.snapshotLocked()
+}
+
+// snapshotLocked 同 Snapshot，调用方需持有 mu
+func (p *EventBus) snapshotLocked() []EventInfo {
+	var infos []EventInfo
+	p.events.Range(func(_, m interface{}) bool {
+		for _, e := range m.([]*event) {
+			infos = append(infos, EventInfo{
+				Key:        e.key,
+				ID:         e.id,
+				Once:       e.once,
+				Version:    e.version,
+				CallTimes:  atomic.LoadInt32(&e.callTimes),
+				Meta:       e.meta,
+				Registered: e.registered,
+			})
+		}
+		return true
+	})
+	sort.SliceStable(infos, func(i, j int) bool {
+		if infos[i].Key != infos[j].Key {
+			return infos[i].Key < infos[j].Key
+		}
+		return infos[i].ID < infos[j].ID
+	})
+	return infos
+}
+
+// MustHaveHandlers 检查 keys 是否都已注册订阅，缺少时返回列出全部缺少事件的错误，
+// 错误可以用 errors.Is 与 ErrNotFound 比较。用于服务启动前校验事件是否全部接好
+func (p *EventBus) MustHaveHandlers(keys ...string) error {
+	p.mu.RLock()
+	var missing []string
+	for _, key := range keys {
+		if len(p.handlers(key)) == 0 {
+			missing = append(missing, key)
+		}
+	}
+	p.mu.RUnlock()
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: missing handlers for %s", ErrNotFound, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// Diff 对比当前的注册信息与之前 Snapshot 的结果，返回新增和移除的订阅，按订阅ID识别
+// 结果的顺序与 Snapshot 一致，新增的订阅使用当前的注册信息。用于发现订阅泄漏或丢失
+func (p *EventBus) Diff(snapshot []EventInfo) (added, removed []EventInfo) {
+	current := p.Snapshot()
+	before := make(map[uint64]bool, len(snapshot))
+	for _, info := range snapshot {
+		before[info.ID] = true
+	}
+	now := make(map[uint64]bool, len(current))
+	for _, info := range current {
+		now[info.ID] = true
+		if !before[info.ID] {
+			added = append(added, info)
+		}
+	}
+	for _, info := range snapshot {
+		if !now[info.ID] {
+			removed = append(removed, info)
+		}
+	}
+	return added, removed
+}
@@ -0,0 +1,72 @@
+package eventbus
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventBus_Keys(t *testing.T) {
+	events := New()
+	defer events.Close()
+	noop := func() {}
+	for _, key := range []string{"order.paid", "a", "user.login", "order.created", "b"} {
+		assert.NoError(t, events.On(key, noop))
+	}
+	assert.Equal(t, []string{"a", "b", "order.created", "order.paid", "user.login"}, events.Keys())
+}
+
+func TestEventBus_Snapshot(t *testing.T) {
+	events := New()
+	defer events.Close()
+	noop := func() {}
+	assert.NoError(t, events.On("z", noop))
+	first, _ := events.Subscribe("m", noop)
+	assert.NoError(t, events.Once("a", noop))
+	second, _ := events.Subscribe("m", noop)
+
+	infos := events.Snapshot()
+	if assert.Len(t, infos, 4) {
+		assert.Equal(t, "a", infos[0].Key)
+		assert.True(t, infos[0].Once)
+		assert.Equal(t, first.ID, infos[1].ID)
+		assert.Equal(t, second.ID, infos[2].ID)
+		assert.Equal(t, "z", infos[3].Key)
+	}
+}
+
+func TestEventBus_MustHaveHandlers(t *testing.T) {
+	events := New()
+	defer events.Close()
+	assert.NoError(t, events.On("order.paid", func() {}))
+	assert.NoError(t, events.MustHaveHandlers("order.paid"))
+
+	err := events.MustHaveHandlers("order.paid", "user.login", "user.logout")
+	assert.True(t, errors.Is(err, ErrNotFound))
+	assert.EqualError(t, err, "event not found: missing handlers for user.login, user.logout")
+}
+
+func TestEventBus_Diff(t *testing.T) {
+	events := New()
+	defer events.Close()
+	assert.NoError(t, events.On("a", func() {}))
+	b, err := events.Subscribe("b", func() {})
+	assert.NoError(t, err)
+	snapshot := events.Snapshot()
+	added, removed := events.Diff(snapshot)
+	assert.Empty(t, added)
+	assert.Empty(t, removed)
+
+	events.Unsubscribe(b)
+	assert.NoError(t, events.On("c", func() {}))
+	assert.NoError(t, events.On("a", func() {}))
+	added, removed = events.Diff(snapshot)
+	if assert.Len(t, added, 2) {
+		assert.Equal(t, "a", added[0].Key)
+		assert.Equal(t, "c", added[1].Key)
+	}
+	if assert.Len(t, removed, 1) {
+		assert.Equal(t, b.ID, removed[0].ID)
+	}
+}
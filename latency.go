@@ -0,0 +1,109 @@
+package eventbus
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// waitSamples 排队时间保留的样本数
+const waitSamples = 1024
+
+// a reservoir 固定容量的样本，写满后覆盖最早的样本，只保留最近的排队时间
+type reservoir struct {
+	mu      sync.Mutex
+	samples []time.Duration
+	next    int
+}
+
+func (r *reservoir) record(d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.samples) < waitSamples {
+		r.samples = append(r.samples, d)
+		return
+	}
+	r.samples[r.next] = d
+	r.next = (r.next + 1) % waitSamples
+}
+
+// percentile 返回样本的 q 分位数(0 < q <= 1)，没有样本时返回 0
+func (r *reservoir) percentile(q float64) time.Duration {
+	r.mu.Lock()
+	sorted := append([]time.Duration(nil), r.samples...)
+	r.mu.Unlock()
+	if len(sorted) == 0 {
+		return 0
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	i := int(q*float64(len(sorted))+0.5) - 1
+	if i < 0 {
+		i = 0
+	}
+	if i >= len(sorted) {
+		i = len(sorted) - 1
+	}
+	return sorted[i]
+}
+
+// QueueWaitP50 返回最近 1024 次发送从入队到出队的排队时间中位数，用于监控订阅器自身的延迟
+func (p *EventBus) QueueWaitP50() time.Duration {
+	return p.waits.percentile(0.5)
+}
+
+// QueueWaitP99 返回最近 1024 次发送排队时间的 99 分位数
+func (p *EventBus) QueueWaitP99() time.Duration {
+	return p.waits.percentile(0.99)
+}
+
+// KeyMetric 一个事件出队调度的统计，用于发现被饿死的事件
+type KeyMetric struct {
+	// Dispatched 出队调度的次数
+	Dispatched int64
+	// TotalWait 全部调度的排队时间之和，MaxWait 为其中最长的一次
+	TotalWait time.Duration
+	MaxWait   time.Duration
+}
+
+// AvgWait 平均排队时间
+func (m KeyMetric) AvgWait() time.Duration {
+	if m.Dispatched == 0 {
+		return 0
+	}
+	return m.TotalWait / time.Duration(m.Dispatched)
+}
+
+// keyMetrics 按事件累计调度次数和排队时间
+type keyMetrics struct {
+	mu   sync.Mutex
+	keys map[string]*KeyMetric
+}
+
+func (k *keyMetrics) record(eventKey string, wait time.Duration) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.keys == nil {
+		k.keys = make(map[string]*KeyMetric)
+	}
+	m, ok := k.keys[eventKey]
+	if !ok {
+		m = &KeyMetric{}
+		k.keys[eventKey] = m
+	}
+	m.Dispatched++
+	m.TotalWait += wait
+	if wait > m.MaxWait {
+		m.MaxWait = wait
+	}
+}
+
+// KeyMetrics 返回每个事件的调度次数和排队时间，只统计经过队列的异步发送
+func (p *EventBus) KeyMetrics() map[string]KeyMetric {
+	p.keyMetrics.mu.Lock()
+	defer p.keyMetrics.mu.Unlock()
+	all := make(map[string]KeyMetric, len(p.keyMetrics.keys))
+	for key, m := range p.keyMetrics.keys {
+		all[key] = *m
+	}
+	return all
+}
@@ -0,0 +1,61 @@
+package eventbus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReservoir(t *testing.T) {
+	var r reservoir
+	assert.Equal(t, time.Duration(0), r.percentile(0.5))
+	for i := 1; i <= 100; i++ {
+		r.record(time.Duration(i) * time.Millisecond)
+	}
+	assert.Equal(t, 50*time.Millisecond, r.percentile(0.5))
+	assert.Equal(t, 99*time.Millisecond, r.percentile(0.99))
+	// 写满后只保留最近的样本
+	for i := 0; i < waitSamples; i++ {
+		r.record(time.Second)
+	}
+	assert.Equal(t, time.Second, r.percentile(0.5))
+}
+
+func TestEventBus_QueueWait(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	events := New(WithClock(clock))
+	assert.NoError(t, events.On("job", func() {}))
+	events.Pause()
+	// 第 i 次发送在出队前排队 100-i 毫秒
+	for i := 0; i < 100; i++ {
+		assert.NoError(t, events.Send("job"))
+		clock.Advance(time.Millisecond)
+	}
+	events.Resume()
+	events.Close()
+	assert.InDelta(t, float64(50*time.Millisecond), float64(events.QueueWaitP50()), float64(2*time.Millisecond))
+	assert.InDelta(t, float64(99*time.Millisecond), float64(events.QueueWaitP99()), float64(2*time.Millisecond))
+}
+
+func TestEventBus_KeyMetrics(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	events := New(WithClock(clock))
+	assert.NoError(t, events.On("hot", func() {}))
+	assert.NoError(t, events.On("cold", func() {}))
+	events.Pause()
+	assert.NoError(t, events.Send("cold"))
+	clock.Advance(time.Second)
+	for i := 0; i < 9; i++ {
+		assert.NoError(t, events.Send("hot"))
+	}
+	clock.Advance(time.Second)
+	events.Resume()
+	events.Close()
+
+	metrics := events.KeyMetrics()
+	assert.Equal(t, int64(9), metrics["hot"].Dispatched)
+	assert.Equal(t, time.Second, metrics["hot"].AvgWait())
+	assert.Equal(t, int64(1), metrics["cold"].Dispatched)
+	assert.Equal(t, 2*time.Second, metrics["cold"].MaxWait)
+}
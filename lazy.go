@@ -0,0 +1,34 @@
+package eventbus
+
+import "reflect"
+
+// OnLazy 注册延迟创建的订阅，首次调用时才执行 factory 生成调用方法并缓存，之后不再执行
+// 用于推迟开销较大的初始化。发送时不校验入参个数，生成的调用方法不可调用时本次调用返回 ErrNotCallable
+// factory 发生 panic 时按订阅的 panic 处理，之后的调用都返回同一个 *PanicError，不再执行 factory
+func (p *EventBus) OnLazy(eventKey string, factory func() interface{}) error {
+	return p.on(&event{key: eventKey, call: factory, lazy: factory}, true)
+}
+
+// invokeLazy 生成并调用缓存的订阅
+func (e *event) invokeLazy(d *dispatch, args []interface{}, fast bool) (out []reflect.Value, err error) {
+	e.lazyOnce.Do(func() {
+		defer func() {
+			if rec := recover(); rec != nil {
+				stack := capturePanicStack()
+				e.recovered(rec, stack)
+				e.lazyErr = &PanicError{Value: rec, Key: e.key, stack: stack}
+			}
+		}()
+		target := &event{key: e.key, call: e.lazy()}
+		if e.lazyErr = e.bus.setup(target); e.lazyErr == nil {
+			e.target = target
+		}
+	})
+	if e.lazyErr != nil {
+		return nil, e.lazyErr
+	}
+	if !e.target.accept(args, e.bus.ignoreExtraArgs) {
+		return nil, ErrArgsNotMatch
+	}
+	return e.target.invoke(d, args, fast)
+}
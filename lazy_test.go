@@ -0,0 +1,59 @@
+package eventbus
+
+import (
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventBus_OnLazy(t *testing.T) {
+	events := New()
+	var built int32
+	got := make(chan int, 3)
+	assert.NoError(t, events.OnLazy("order", func() interface{} {
+		atomic.AddInt32(&built, 1)
+		return func(n int) { got <- n }
+	}))
+	// 注册时不创建
+	assert.Equal(t, int32(0), atomic.LoadInt32(&built))
+
+	for i := 1; i <= 3; i++ {
+		assert.NoError(t, events.Send("order", i))
+	}
+	events.Close()
+	assert.Equal(t, int32(1), built)
+	assert.ElementsMatch(t, []int{1, 2, 3}, []int{<-got, <-got, <-got})
+}
+
+func TestEventBus_OnLazyNotCallable(t *testing.T) {
+	events := New()
+	defer events.Close()
+	assert.NoError(t, events.OnLazy("bad", func() interface{} { return 1 }))
+	e := events.handlers("bad")[0]
+	assert.Equal(t, ErrNotCallable, e.Call(nil))
+
+	assert.NoError(t, events.OnLazy("add", func() interface{} { return func(a, b int) {} }))
+	e = events.handlers("add")[0]
+	assert.Equal(t, ErrArgsNotMatch, e.Call([]interface{}{1}))
+	assert.NoError(t, e.Call([]interface{}{1, 2}))
+}
+
+func TestEventBus_OnLazyFactoryPanic(t *testing.T) {
+	events := New(WithSilentPanics())
+	defer events.Close()
+	var built int32
+	assert.NoError(t, events.OnLazy("boom", func() interface{} {
+		atomic.AddInt32(&built, 1)
+		panic("factory failed")
+	}))
+	e := events.handlers("boom")[0]
+	// 之后的调用返回同一个错误，不会因为订阅为空而 panic
+	for i := 0; i < 2; i++ {
+		err := e.Call(nil)
+		var perr *PanicError
+		assert.ErrorAs(t, err, &perr)
+		assert.Equal(t, "factory failed", perr.Value)
+	}
+	assert.Equal(t, int32(1), built)
+}
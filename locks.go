@@ -0,0 +1,92 @@
+package eventbus
+
+import (
+	"bytes"
+	"runtime"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+// lockStripes WithKeyLock 分段锁的数量，不同事件可能落在同一段上
+const lockStripes = 64
+
+// stripe 计算事件所在的分段
+func stripe(eventKey string) uint32 {
+	return keyHash(eventKey) % lockStripes
+}
+
+// keyHash 使用 FNV-1a 计算事件的摘要
+func keyHash(eventKey string) uint32 {
+	h := uint32(2166136261)
+	for i := 0; i < len(eventKey); i++ {
+		h ^= uint32(eventKey[i])
+		h *= 16777619
+	}
+	return h
+}
+
+// keyLock 返回事件所在分段的锁
+func (p *EventBus) keyLock(eventKey string) *sync.Mutex {
+	return &p.keyLocks[stripe(eventKey)]
+}
+
+// WithKeyLock 持有事件对应的锁执行 fn，共享同一事件状态的订阅可以借此互斥，无需自行维护锁表
+// 锁按事件的 FNV-1a 摘要分段，只供 WithKeyLock 使用，订阅器内部不持有，与 OnSerialized 的事件锁互不影响。
+// 不同事件可能落在同一段上，fn 中不要再获取其他事件的锁
+func (p *EventBus) WithKeyLock(eventKey string, fn func()) {
+	l := p.keyLock(eventKey)
+	l.Lock()
+	defer l.Unlock()
+	fn()
+}
+
+// OnSerialized 注册订阅器，同一事件的调用依次执行，不会并发，其他事件依旧并发调用
+// 每个事件使用独立的锁，与 WithKeyLock 的分段锁互不影响，同一事件的多个 OnSerialized 订阅共用一把锁
+// 同步发送的订阅中向自身事件同步发送时传入注入的 context 可以重入，其他情况下重入返回 ErrReentrantDeadlock 而不是死锁
+func (p *EventBus) OnSerialized(eventKey string, call interface{}) error {
+	return p.on(&event{key: eventKey, call: call, serial: p.serialLock(eventKey)}, true)
+}
+
+// serialLock 返回 OnSerialized 使用的事件锁，首次使用时创建
+func (p *EventBus) serialLock(eventKey string) *serialMutex {
+	if l, ok := p.serialLocks.Load(eventKey); ok {
+		return l.(*serialMutex)
+	}
+	l, _ := p.serialLocks.LoadOrStore(eventKey, new(serialMutex))
+	return l.(*serialMutex)
+}
+
+// a serialMutex OnSerialized 的事件锁，记录持有锁的协程，用于发现同一协程重复加锁造成的死锁
+type serialMutex struct {
+	mu    sync.Mutex
+	owner int64
+}
+
+func (m *serialMutex) Lock() {
+	m.mu.Lock()
+	atomic.StoreInt64(&m.owner, goid())
+}
+
+func (m *serialMutex) Unlock() {
+	atomic.StoreInt64(&m.owner, 0)
+	m.mu.Unlock()
+}
+
+// ownedByCaller 当前协程持有该锁
+func (m *serialMutex) ownedByCaller() bool {
+	owner := atomic.LoadInt64(&m.owner)
+	return owner != 0 && owner == goid()
+}
+
+// goid 从调用栈的第一行 "goroutine N [...]" 中解析当前协程的ID
+func goid() int64 {
+	var buf [64]byte
+	line := buf[:runtime.Stack(buf[:], false)]
+	line = bytes.TrimPrefix(line, []byte("goroutine "))
+	if i := bytes.IndexByte(line, ' '); i > 0 {
+		line = line[:i]
+	}
+	id, _ := strconv.ParseInt(string(line), 10, 64)
+	return id
+}
@@ -0,0 +1,143 @@
+package eventbus
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventBus_WithKeyLock(t *testing.T) {
+	events := New()
+	defer events.Close()
+
+	// 同一事件互斥
+	var running, overlap int32
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			events.WithKeyLock("counter", func() {
+				if atomic.AddInt32(&running, 1) > 1 {
+					atomic.StoreInt32(&overlap, 1)
+				}
+				time.Sleep(time.Millisecond)
+				atomic.AddInt32(&running, -1)
+			})
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(0), overlap)
+
+	// 不同分段的事件可以同时持有锁
+	other := "other"
+	for stripe(other) == stripe("counter") {
+		other += "_"
+	}
+	events.WithKeyLock("counter", func() {
+		done := make(chan struct{})
+		go events.WithKeyLock(other, func() {
+			close(done)
+		})
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("different keys blocked each other")
+		}
+	})
+}
+
+func TestEventBus_OnSerialized(t *testing.T) {
+	events := New()
+	// track 返回记录同时执行的最大调用数的订阅
+	track := func(peak *int32) func() {
+		var running int32
+		return func() {
+			n := atomic.AddInt32(&running, 1)
+			for old := atomic.LoadInt32(peak); n > old; old = atomic.LoadInt32(peak) {
+				if atomic.CompareAndSwapInt32(peak, old, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			atomic.AddInt32(&running, -1)
+		}
+	}
+	var serial, parallel int32
+	assert.NoError(t, events.OnSerialized("serial", track(&serial)))
+	assert.NoError(t, events.On("parallel", track(&parallel)))
+	for i := 0; i < 10; i++ {
+		assert.NoError(t, events.Send("serial"))
+		assert.NoError(t, events.Send("parallel"))
+	}
+	events.Close()
+
+	assert.Equal(t, int32(1), serial)
+	assert.True(t, parallel > 1, "parallel peak %d", parallel)
+}
+
+func TestEventBus_OnSerializedKeyLock(t *testing.T) {
+	events := New()
+	// 找到与 a 落在同一分段的事件
+	other := ""
+	for i := 0; other == ""; i++ {
+		if k := fmt.Sprintf("k%d", i); stripe(k) == stripe("a") {
+			other = k
+		}
+	}
+	done := make(chan struct{})
+	assert.NoError(t, events.OnSerialized("a", func() {
+		events.WithKeyLock(other, func() {})
+		close(done)
+	}))
+	assert.NoError(t, events.Send("a"))
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("serialized handler deadlocked on key lock stripe")
+	}
+	events.Close()
+}
+
+func TestEventBus_OnSerializedReentrant(t *testing.T) {
+	events := New()
+	defer events.Close()
+	errs := make(chan error, 2)
+	assert.NoError(t, events.OnSerialized("tick", func(ctx context.Context, n int) {
+		if n > 0 {
+			return
+		}
+		// 传入注入的 context 时可以重入
+		errs <- events.SendSyncCtx(ctx, "tick", 1)
+		// 丢失 context 时无法识别调用链，返回错误而不是死锁
+		errs <- events.SendSync("tick", 1)
+	}))
+	assert.NoError(t, events.SendSync("tick", 0))
+	assert.NoError(t, <-errs)
+	assert.Equal(t, ErrReentrantDeadlock, <-errs)
+
+	// 异步调用中持有的锁不属于任何同步调用链，重入同样返回错误
+	assert.NoError(t, events.Send("tick", 0))
+	for i := 0; i < 2; i++ {
+		select {
+		case err := <-errs:
+			assert.Equal(t, ErrReentrantDeadlock, err)
+		case <-time.After(time.Second):
+			t.Fatal("re-entrant send hung")
+		}
+	}
+}
+
+func TestGoid(t *testing.T) {
+	ids := make(chan int64)
+	go func() { ids <- goid() }()
+	other := <-ids
+	assert.NotZero(t, goid())
+	assert.NotZero(t, other)
+	assert.NotEqual(t, goid(), other)
+}
@@ -0,0 +1,49 @@
+package eventbus
+
+import "reflect"
+
+// OnWithMeta 注册带元数据的订阅，元数据用于标记订阅的负责人、模块、SLA 等，同一事件允许注册多个订阅
+// 元数据在注册时复制，可以通过 Snapshot 和中间件的 Invocation 读取
+func (p *EventBus) OnWithMeta(eventKey string, meta map[string]interface{}, call interface{}) error {
+	var copied map[string]interface{}
+	if len(meta) > 0 {
+		copied = make(map[string]interface{}, len(meta))
+		for k, v := range meta {
+			copied[k] = v
+		}
+	}
+	return p.on(&event{key: eventKey, call: call, meta: copied}, false)
+}
+
+// SendTagged 只调用元数据与 tagFilter 全部匹配的订阅，用于定向广播，如只通知 region=us 的订阅
+// 没有匹配的订阅时返回 ErrNotFound
+func (p *EventBus) SendTagged(eventKey string, tagFilter map[string]interface{}, args ...interface{}) error {
+	return p.send(&sender{key: eventKey, args: args, filter: func(e *event) bool {
+		return e.matchMeta(tagFilter)
+	}})
+}
+
+// matchMeta 订阅的元数据包含 filter 中的全部键值
+func (e *event) matchMeta(filter map[string]interface{}) bool {
+	for k, v := range filter {
+		got, ok := e.meta[k]
+		if !ok || !reflect.DeepEqual(got, v) {
+			return false
+		}
+	}
+	return true
+}
+
+// filtered 返回 handlers 中满足 keep 的订阅，全部不满足时返回 ErrNotFound
+func filtered(handlers []*event, keep func(e *event) bool) ([]*event, error) {
+	var kept []*event
+	for _, e := range handlers {
+		if keep(e) {
+			kept = append(kept, e)
+		}
+	}
+	if len(kept) == 0 {
+		return nil, ErrNotFound
+	}
+	return kept, nil
+}
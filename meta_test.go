@@ -0,0 +1,46 @@
+package eventbus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventBus_OnWithMeta(t *testing.T) {
+	events := New()
+	defer events.Close()
+	meta := map[string]interface{}{"owner": "billing", "sla": 100}
+	assert.NoError(t, events.OnWithMeta("pay", meta, func() {}))
+	assert.NoError(t, events.On("pay", func() {}))
+	// 注册后修改不影响已注册的元数据
+	meta["owner"] = "other"
+
+	infos := events.Snapshot()
+	if assert.Len(t, infos, 2) {
+		assert.Equal(t, map[string]interface{}{"owner": "billing", "sla": 100}, infos[0].Meta)
+		assert.Nil(t, infos[1].Meta)
+	}
+
+	owners := make(chan interface{}, 2)
+	events.Use(func(inv Invocation, next func() error) error {
+		owners <- inv.Meta["owner"]
+		return next()
+	})
+	assert.NoError(t, events.SendSync("pay"))
+	assert.ElementsMatch(t, []interface{}{"billing", nil}, []interface{}{<-owners, <-owners})
+}
+
+func TestEventBus_SendTagged(t *testing.T) {
+	events := New()
+	defer events.Close()
+	got := make(chan string, 3)
+	assert.NoError(t, events.OnWithMeta("deploy", map[string]interface{}{"region": "us"}, func() { got <- "us" }))
+	assert.NoError(t, events.OnWithMeta("deploy", map[string]interface{}{"region": "eu"}, func() { got <- "eu" }))
+	assert.NoError(t, events.On("deploy", func() { got <- "untagged" }))
+
+	assert.NoError(t, events.SendTagged("deploy", map[string]interface{}{"region": "us"}))
+	assert.Equal(t, "us", <-got)
+	assert.Equal(t, ErrNotFound, events.SendTagged("deploy", map[string]interface{}{"region": "cn"}))
+	events.Close()
+	assert.Len(t, got, 0)
+}
@@ -0,0 +1,58 @@
+package eventbus
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// counters 发送和调用的计数，使用原子操作更新
+type counters struct {
+	sent     int64
+	failed   int64
+	inFlight int64
+}
+
+// Stats 订阅器的统计数据
+type Stats struct {
+	// Sent 成功放入队列的发送次数
+	Sent int64
+	// Failed 执行出错(含 panic)的发送次数
+	Failed int64
+	// QueueDepth 队列中等待调用的发送数量
+	QueueDepth int
+	// InFlight 正在执行的发送数量
+	InFlight int64
+}
+
+// Stats 返回当前的统计数据
+func (p *EventBus) Stats() Stats {
+	return Stats{
+		Sent:       atomic.LoadInt64(&p.stats.sent),
+		Failed:     atomic.LoadInt64(&p.stats.failed),
+		QueueDepth: p.queue.len(),
+		InFlight:   atomic.LoadInt64(&p.stats.inFlight),
+	}
+}
+
+// WithMetricsEvent 每隔 interval 以 eventKey 发出一次 Stats，订阅 func(Stats) 即可收集订阅器自身的统计
+func WithMetricsEvent(eventKey string, interval time.Duration) Option {
+	return func(p *EventBus) {
+		p.metrics.key = eventKey
+		p.metrics.interval = interval
+	}
+}
+
+// emitMetrics 定时发出统计事件，直到订阅器关闭
+func (p *EventBus) emitMetrics() {
+	ticker := time.NewTicker(p.metrics.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.done:
+			return
+		case <-ticker.C:
+			// 没有订阅时忽略
+			p.send(&sender{key: p.metrics.key, args: []interface{}{p.Stats()}})
+		}
+	}
+}
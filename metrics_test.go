@@ -0,0 +1,56 @@
+package eventbus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventBus_Stats(t *testing.T) {
+	events := New()
+	done := make(chan struct{})
+	events.On("wait", func() { <-done })
+	events.On("panic", makePanic)
+
+	events.Send("wait")
+	events.Send("panic")
+	assert.Eventually(t, func() bool {
+		s := events.Stats()
+		return s.InFlight == 1 && s.Failed == 1
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, int64(2), events.Stats().Sent)
+
+	close(done)
+	events.Close()
+	assert.Equal(t, Stats{Sent: 2, Failed: 1}, events.Stats())
+}
+
+func TestEventBus_WithMetricsEvent(t *testing.T) {
+	events := New(WithMetricsEvent("metrics", 10*time.Millisecond))
+	defer events.Close()
+
+	got := make(chan Stats, 16)
+	events.On("metrics", func(s Stats) {
+		select {
+		case got <- s:
+		default:
+		}
+	})
+	events.On("add", func(a, b int) {})
+	events.Send("add", 1, 2)
+
+	deadline := time.After(time.Second)
+	for {
+		select {
+		case s := <-got:
+			assert.True(t, s.Failed == 0 && s.QueueDepth >= 0 && s.InFlight >= 0)
+			// add 和之前的统计事件都已计入
+			if s.Sent >= 2 {
+				return
+			}
+		case <-deadline:
+			t.Fatal("metrics event not received")
+		}
+	}
+}
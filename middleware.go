@@ -0,0 +1,129 @@
+package eventbus
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Invocation 一次订阅调用的信息，Key 为实际发送的事件
+type Invocation struct {
+	Key       string
+	HandlerID uint64
+	Args      []interface{}
+	// Meta 订阅通过 OnWithMeta 附加的元数据，不可修改
+	Meta map[string]interface{}
+}
+
+// Middleware 包装每一次订阅调用，调用 next 执行后续的中间件和订阅，返回值作为订阅的执行结果
+// 不调用 next 时订阅不会执行
+type Middleware func(inv Invocation, next func() error) error
+
+// a middleware 中间件链中的一项
+type middleware struct {
+	name string
+	mw   Middleware
+}
+
+// middlewares 中间件链，修改时整体替换，调用中读取的链不受并发修改影响
+type middlewares struct {
+	mu    sync.Mutex
+	chain atomic.Value
+}
+
+func (m *middlewares) load() []middleware {
+	chain, _ := m.chain.Load().([]middleware)
+	return chain
+}
+
+// update 在锁内基于当前的链构建新的链
+func (m *middlewares) update(fn func(chain []middleware) ([]middleware, bool)) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	chain := append([]middleware(nil), m.load()...)
+	next, ok := fn(chain)
+	if ok {
+		m.chain.Store(next)
+	}
+	return ok
+}
+
+func indexMiddleware(chain []middleware, name string) int {
+	for i, m := range chain {
+		if m.name != "" && m.name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// Use 在中间件链末尾追加匿名中间件，先追加的中间件在外层
+func (p *EventBus) Use(mw Middleware) {
+	p.middlewares.update(func(chain []middleware) ([]middleware, bool) {
+		return append(chain, middleware{mw: mw}), true
+	})
+}
+
+// UseNamed 在中间件链末尾追加具名中间件，同名的中间件已经存在时原位替换
+func (p *EventBus) UseNamed(name string, mw Middleware) {
+	p.middlewares.update(func(chain []middleware) ([]middleware, bool) {
+		if i := indexMiddleware(chain, name); i >= 0 {
+			chain[i].mw = mw
+			return chain, true
+		}
+		return append(chain, middleware{name: name, mw: mw}), true
+	})
+}
+
+// UseNamedBefore 把具名中间件插入到 before 之前，同名的中间件已经存在时先移除，before 不存在时返回 ErrNotFound
+func (p *EventBus) UseNamedBefore(before, name string, mw Middleware) error {
+	ok := p.middlewares.update(func(chain []middleware) ([]middleware, bool) {
+		if i := indexMiddleware(chain, name); i >= 0 && name != before {
+			chain = append(chain[:i], chain[i+1:]...)
+		}
+		i := indexMiddleware(chain, before)
+		if i < 0 || name == before {
+			return nil, false
+		}
+		chain = append(chain[:i], append([]middleware{{name: name, mw: mw}}, chain[i:]...)...)
+		return chain, true
+	})
+	if !ok {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// RemoveMiddleware 移除具名中间件，不存在时返回 false，已经开始的调用不受影响
+func (p *EventBus) RemoveMiddleware(name string) bool {
+	return p.middlewares.update(func(chain []middleware) ([]middleware, bool) {
+		i := indexMiddleware(chain, name)
+		if i < 0 {
+			return nil, false
+		}
+		return append(chain[:i], chain[i+1:]...), true
+	})
+}
+
+// MiddlewareNames 按调用顺序(由外到内)返回中间件的名称，匿名中间件为空字符串
+func (p *EventBus) MiddlewareNames() []string {
+	chain := p.middlewares.load()
+	names := make([]string, len(chain))
+	for i, m := range chain {
+		names[i] = m.name
+	}
+	return names
+}
+
+// wrap 使用当前的中间件链包装订阅 e 的调用 fn
+func (p *EventBus) wrap(eventKey string, e *event, args []interface{}, fn func() error) func() error {
+	chain := p.middlewares.load()
+	if len(chain) == 0 {
+		return fn
+	}
+	inv := Invocation{Key: eventKey, HandlerID: e.id, Args: args, Meta: e.meta}
+	for i := len(chain) - 1; i >= 0; i-- {
+		mw, next := chain[i].mw, fn
+		fn = func() error { return mw(inv, next) }
+	}
+	return fn
+}
@@ -0,0 +1,80 @@
+package eventbus
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventBus_Middleware(t *testing.T) {
+	events := New()
+	defer events.Close()
+	var mu sync.Mutex
+	var calls []string
+	trace := func(name string) Middleware {
+		return func(inv Invocation, next func() error) error {
+			mu.Lock()
+			calls = append(calls, name+":"+inv.Key)
+			mu.Unlock()
+			return next()
+		}
+	}
+	run := func() []string {
+		mu.Lock()
+		calls = nil
+		mu.Unlock()
+		assert.NoError(t, events.SendSync("job", 1))
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), calls...)
+	}
+	assert.NoError(t, events.On("job", func(int) {
+		mu.Lock()
+		calls = append(calls, "handler")
+		mu.Unlock()
+	}))
+
+	events.UseNamed("auth", trace("auth"))
+	events.UseNamed("metrics", trace("metrics"))
+	assert.NoError(t, events.UseNamedBefore("auth", "tracing", trace("tracing")))
+	assert.Equal(t, []string{"tracing", "auth", "metrics"}, events.MiddlewareNames())
+	assert.Equal(t, []string{"tracing:job", "auth:job", "metrics:job", "handler"}, run())
+
+	// 重新排序与移除
+	assert.NoError(t, events.UseNamedBefore("tracing", "metrics", trace("metrics")))
+	assert.Equal(t, []string{"metrics", "tracing", "auth"}, events.MiddlewareNames())
+	assert.True(t, events.RemoveMiddleware("tracing"))
+	assert.False(t, events.RemoveMiddleware("tracing"))
+	assert.Equal(t, ErrNotFound, events.UseNamedBefore("missing", "x", trace("x")))
+	assert.Equal(t, []string{"metrics:job", "auth:job", "handler"}, run())
+
+	// 中间件可以拦截调用
+	events.UseNamed("auth", func(inv Invocation, next func() error) error {
+		return errors.New("denied")
+	})
+	assert.EqualError(t, events.SendSync("job", 1), "denied")
+}
+
+func TestEventBus_MiddlewareConcurrent(t *testing.T) {
+	events := New()
+	assert.NoError(t, events.On("job", func() {}))
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				events.UseNamed("toggle", func(inv Invocation, next func() error) error { return next() })
+				events.RemoveMiddleware("toggle")
+			}
+		}()
+	}
+	for i := 0; i < 200; i++ {
+		assert.NoError(t, events.Send("job"))
+	}
+	wg.Wait()
+	events.Close()
+	assert.Equal(t, int64(0), events.Stats().Failed)
+}
@@ -0,0 +1,55 @@
+package eventbus
+
+import "sync/atomic"
+
+// Migrate 停止接受新的发送，之后的发送返回 ErrBusClosed，并把队列中尚未调用的事件按入队顺序转发到 dst
+// 转发的事件在 dst 中重新查找订阅，订阅需要另行在 dst 中注册
+// 转发失败(如 dst 没有对应的订阅)的事件被丢弃，返回遇到的第一个错误
+// 转发的事件在本订阅器的预写日志中确认，SendTracked 的结果记为 ErrMigrated 或转发失败的错误
+func (p *EventBus) Migrate(dst *EventBus) error {
+	p.closeMu.Lock()
+	atomic.StoreInt32(&p.closed, 1)
+	p.closeMu.Unlock()
+	// WithAtomicClose 下已经准入的发送先完成入队，一起转发
+	p.admitting.Wait()
+	var first error
+	for _, s := range p.queue.takeAll() {
+		p.release()
+		p.ackSend(s)
+		if s.ctx != nil && s.ctx.Err() != nil {
+			p.finishResult(s, s.ctx.Err())
+			continue
+		}
+		next := &sender{
+			key:      s.key,
+			args:     s.args,
+			priority: s.priority,
+			ctx:      s.ctx,
+			version:  s.version,
+			results:  s.results,
+			bubble:   s.bubble,
+			ack:      s.ack,
+		}
+		if s.dequeued != nil {
+			next.dequeued = make(chan struct{})
+		}
+		err := dst.send(next)
+		if err != nil {
+			// 转发失败的事件不会再被调用，结束 SendNotifyAll 的等待
+			if s.results != nil {
+				close(s.results)
+			}
+			if first == nil {
+				first = err
+			}
+		} else {
+			err = ErrMigrated
+		}
+		p.finishResult(s, err)
+	}
+	return first
+}
+
+func (p *EventBus) isClosed() bool {
+	return atomic.LoadInt32(&p.closed) == 1
+}
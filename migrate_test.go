@@ -0,0 +1,111 @@
+package eventbus
+
+import (
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventBus_Migrate(t *testing.T) {
+	src := New()
+	defer src.Close()
+	src.Pause()
+	assert.NoError(t, src.On("job", func(int) { t.Error("called on source") }))
+	for i := 1; i <= 3; i++ {
+		assert.NoError(t, src.Send("job", i))
+	}
+
+	dst := New()
+	var (
+		mu       sync.Mutex
+		received []int
+	)
+	assert.NoError(t, dst.On("job", func(i int) {
+		mu.Lock()
+		received = append(received, i)
+		mu.Unlock()
+	}))
+	assert.NoError(t, src.Migrate(dst))
+	assert.Empty(t, src.PendingSnapshot())
+	assert.EqualError(t, src.Send("job", 4), ErrBusClosed.Error())
+	dst.Close()
+
+	assert.ElementsMatch(t, []int{1, 2, 3}, received)
+}
+
+func TestEventBus_MigrateNotFound(t *testing.T) {
+	src := New()
+	defer src.Close()
+	src.Pause()
+	assert.NoError(t, src.On("job", func() {}))
+	assert.NoError(t, src.Send("job"))
+
+	dst := New()
+	defer dst.Close()
+	assert.EqualError(t, src.Migrate(dst), ErrNotFound.Error())
+}
+
+func TestEventBus_MigrateNotifyFailed(t *testing.T) {
+	src := New()
+	dst := New()
+	defer dst.Close()
+	assert.NoError(t, src.On("job", func() {}))
+	src.Pause()
+	results, err := src.SendNotifyAll("job")
+	assert.NoError(t, err)
+	// dst 没有订阅，转发失败
+	assert.Equal(t, ErrNotFound, src.Migrate(dst))
+	done := make(chan struct{})
+	go func() {
+		for range results {
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("notify results not closed after failed migration")
+	}
+	src.Resume()
+	src.Close()
+}
+
+func TestEventBus_MigrateWALAndResults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.wal")
+	src := New(WithWAL(path))
+	src.Pause()
+	assert.NoError(t, src.On("job", func(int) {}))
+	moved, err := src.SendTracked("job", 1)
+	assert.NoError(t, err)
+	lost, err := src.SendTracked("lost", 2)
+	assert.Equal(t, ErrNotFound, err)
+	assert.Equal(t, uint64(0), lost)
+	src.On("lost", func(int) {})
+	failed, err := src.SendTracked("lost", 2)
+	assert.NoError(t, err)
+
+	dst := New()
+	assert.NoError(t, dst.On("job", func(int) {}))
+	assert.Equal(t, ErrNotFound, src.Migrate(dst))
+	dst.Close()
+	src.Close()
+
+	// 转发和转发失败的发送都已经结束
+	done, err := src.Result(moved)
+	assert.True(t, done)
+	assert.Equal(t, ErrMigrated, err)
+	done, err = src.Result(failed)
+	assert.True(t, done)
+	assert.Equal(t, ErrNotFound, err)
+
+	// 转发的事件在源订阅器的日志中已经确认，重启后不会重复投递
+	restarted := New(WithWAL(path))
+	defer restarted.Close()
+	assert.NoError(t, restarted.On("job", func(int) { t.Error("replayed migrated send") }))
+	n, err := restarted.ReplayWAL()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, n)
+}
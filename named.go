@@ -0,0 +1,31 @@
+package eventbus
+
+// OnNamed 注册具名订阅并返回订阅句柄，同一事件内名称唯一，重复的名称返回 ErrDuplicateName
+// 同一事件允许注册多个不同名称的订阅，可以通过 Named 按名称取得句柄后调用 SendTo 或 Unsubscribe
+func (p *EventBus) OnNamed(eventKey, name string, call interface{}) (Subscription, error) {
+	e := &event{key: eventKey, call: call, name: name}
+	if err := p.on(e, false); err != nil {
+		return Subscription{}, err
+	}
+	return Subscription{Key: eventKey, ID: e.id}, nil
+}
+
+// Named 返回事件下指定名称的订阅句柄
+func (p *EventBus) Named(eventKey, name string) (Subscription, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if e := named(p.handlers(eventKey), name); e != nil {
+		return Subscription{Key: eventKey, ID: e.id}, true
+	}
+	return Subscription{}, false
+}
+
+// named 在 handlers 中查找指定名称的订阅
+func named(handlers []*event, name string) *event {
+	for _, e := range handlers {
+		if e.name == name {
+			return e
+		}
+	}
+	return nil
+}
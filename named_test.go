@@ -0,0 +1,31 @@
+package eventbus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventBus_OnNamed(t *testing.T) {
+	events := New()
+	defer events.Close()
+	audit, err := events.OnNamed("order", "audit", func(int) {})
+	assert.NoError(t, err)
+	_, err = events.OnNamed("order", "audit", func(int) {})
+	assert.Equal(t, ErrDuplicateName, err)
+	// 其他名称或其他事件不受影响
+	_, err = events.OnNamed("order", "billing", func(int) {})
+	assert.NoError(t, err)
+	_, err = events.OnNamed("refund", "audit", func(int) {})
+	assert.NoError(t, err)
+
+	handle, ok := events.Named("order", "audit")
+	assert.True(t, ok)
+	assert.Equal(t, audit, handle)
+	events.Unsubscribe(handle)
+	_, ok = events.Named("order", "audit")
+	assert.False(t, ok)
+	// 移除后名称可以重新注册
+	_, err = events.OnNamed("order", "audit", func(int) {})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,17 @@
+package eventbus
+
+// HandlerResult 一个订阅的调用结果
+type HandlerResult struct {
+	HandlerID uint64
+	Err       error
+}
+
+// SendNotifyAll 调用事件，返回的 channel 在每个订阅调用结束后写入一次结果，全部调用结束后关闭
+// 停止传播后未调用的订阅没有结果，被 WithContentDedup 合并的发送返回已经关闭的 channel
+func (p *EventBus) SendNotifyAll(eventKey string, args ...interface{}) (<-chan HandlerResult, error) {
+	s := &sender{key: eventKey, args: args, notify: true}
+	if err := p.send(s); err != nil {
+		return nil, err
+	}
+	return s.results, nil
+}
@@ -0,0 +1,51 @@
+package eventbus
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventBus_SendNotifyAll(t *testing.T) {
+	events := New()
+	defer events.Close()
+	failed := errors.New("failed")
+	ok, _ := events.Subscribe("job", func(int) {})
+	bad, _ := events.Subscribe("job", func(int) error { return failed })
+	boom, _ := events.Subscribe("job", func(int) { panic("boom") })
+
+	results, err := events.SendNotifyAll("job", 1)
+	assert.NoError(t, err)
+	got := map[uint64]error{}
+	for r := range results {
+		got[r.HandlerID] = r.Err
+	}
+	assert.ErrorIs(t, got[boom.ID], ErrRuntimePanic)
+	delete(got, boom.ID)
+	assert.Equal(t, map[uint64]error{ok.ID: nil, bad.ID: failed}, got)
+
+	_, err = events.SendNotifyAll("missing")
+	assert.EqualError(t, err, ErrNotFound.Error())
+}
+
+func TestSendNotifyAll_Dedup(t *testing.T) {
+	events := New(WithContentDedup(time.Hour))
+	defer events.Close()
+	assert.NoError(t, events.On("job", func(int) {}))
+	first, err := events.SendNotifyAll("job", 1)
+	assert.NoError(t, err)
+	assert.Len(t, collect(first), 1)
+	dup, err := events.SendNotifyAll("job", 1)
+	assert.NoError(t, err)
+	assert.Empty(t, collect(dup))
+}
+
+func collect(ch <-chan HandlerResult) []HandlerResult {
+	var results []HandlerResult
+	for r := range ch {
+		results = append(results, r)
+	}
+	return results
+}
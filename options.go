@@ -0,0 +1,96 @@
+package eventbus
+
+import "fmt"
+
+// Logger 订阅器输出日志的接口，log.Logger 满足该接口
+type Logger interface {
+	Printf(format string, args ...interface{})
+}
+
+// stdLogger 默认日志，输出到标准输出
+type stdLogger struct{}
+
+func (stdLogger) Printf(format string, args ...interface{}) {
+	fmt.Printf(format+"\n", args...)
+}
+
+// WithLogger 使用 logger 输出订阅器的日志
+func WithLogger(logger Logger) Option {
+	return func(p *EventBus) {
+		p.logger = logger
+	}
+}
+
+// WithMaxHandlers 限制全部事件的订阅总数，达到 n 之后 On/Once/Subscribe/OnAny 返回 ErrHandlerLimit
+// 用于防止长期运行的进程中订阅泄漏，OnAny 的全量订阅计入总数，兜底订阅不计入
+func WithMaxHandlers(n int) Option {
+	return func(p *EventBus) {
+		p.maxHandlers = n
+	}
+}
+
+// WithExtraArgsIgnored 发送的入参多于订阅需要的个数时只传入前面的入参，不再返回 ErrArgsNotMatch
+// 入参少于订阅需要的个数依旧返回 ErrArgsNotMatch
+func WithExtraArgsIgnored() Option {
+	return func(p *EventBus) {
+		p.ignoreExtraArgs = true
+	}
+}
+
+// WithSingleHandlerMode 兼容旧版本的单订阅语义，同一事件(版本)已有订阅时 On、Once、OnVersion、OnRaw、
+// OnWithTTL、OnSerialized、OnLazy 返回 ErrExists。Subscribe、OnGroup 等多订阅的注册方法不受影响
+func WithSingleHandlerMode() Option {
+	return func(p *EventBus) {
+		p.singleHandler = true
+	}
+}
+
+// WithStrictUnknown Send 未注册的事件时直接 panic，不再返回 ErrNotFound，用于开发环境尽早暴露接线错误。
+// panic 的值为包装了 ErrNotFound 的 error。只作用于 Send 和 SendEncoded，SendWaitSub、SendRetained、SendAfter、Reply 以及指标上报依旧返回或忽略 ErrNotFound
+func WithStrictUnknown() Option {
+	return func(p *EventBus) {
+		p.strictUnknown = true
+	}
+}
+
+// WithMaxGoroutines 限制排队和执行中的发送总数为 n，每个发送在调用时占用一个协程，
+// 达到上限后 Send 阻塞直到有发送执行完成，防止突发流量耗尽协程。
+// 订阅中同步发送时可能因等待自身占用的名额而死锁，此时配合 WithGoroutineLimitFailFast 使用
+func WithMaxGoroutines(n int) Option {
+	return func(p *EventBus) {
+		if n > 0 {
+			p.slots = make(chan struct{}, n)
+		}
+	}
+}
+
+// WithGoroutineLimitFailFast 达到 WithMaxGoroutines 的上限时 Send 不再阻塞，直接返回 ErrGoroutineLimit
+func WithGoroutineLimitFailFast() Option {
+	return func(p *EventBus) {
+		p.failFast = true
+	}
+}
+
+// acquire 为一次发送占用名额
+func (p *EventBus) acquire() error {
+	if p.slots == nil {
+		return nil
+	}
+	if !p.failFast {
+		p.slots <- struct{}{}
+		return nil
+	}
+	select {
+	case p.slots <- struct{}{}:
+		return nil
+	default:
+		return ErrGoroutineLimit
+	}
+}
+
+// release 发送执行完成或被移出队列后归还名额
+func (p *EventBus) release() {
+	if p.slots != nil {
+		<-p.slots
+	}
+}
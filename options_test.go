@@ -0,0 +1,157 @@
+package eventbus
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithExtraArgsIgnored(t *testing.T) {
+	events := New(WithExtraArgsIgnored())
+	defer events.Close()
+
+	got := make(chan int, 1)
+	events.On("add", func(a, b int) {
+		got <- a + b
+	})
+	err := events.Send("add", 1, 2, 3, "extra")
+	assert.NoError(t, err)
+	select {
+	case v := <-got:
+		assert.Equal(t, 3, v)
+	case <-time.After(time.Second):
+		t.Fatal("event not received")
+	}
+
+	err = events.Send("add", 1)
+	assert.EqualError(t, err, ErrArgsNotMatch.Error())
+
+	// 默认不允许多余的入参
+	strict := New()
+	defer strict.Close()
+	strict.On("add", func(a, b int) {})
+	err = strict.Send("add", 1, 2, 3)
+	assert.EqualError(t, err, ErrArgsNotMatch.Error())
+}
+
+func TestWithMaxHandlers(t *testing.T) {
+	events := New(WithMaxHandlers(3))
+	defer events.Close()
+	noop := func() {}
+
+	assert.NoError(t, events.On("a", noop))
+	_, err := events.Subscribe("b", noop)
+	assert.NoError(t, err)
+	_, err = events.Subscribe("b", noop)
+	assert.NoError(t, err)
+	err = events.Once("c", noop)
+	assert.EqualError(t, err, ErrHandlerLimit.Error())
+
+	// 移除之后可以继续注册
+	events.Remove("b")
+	assert.NoError(t, events.Once("c", noop))
+	// once 执行后释放名额
+	assert.NoError(t, events.Send("c"))
+	assert.NoError(t, events.On("d", noop))
+	assert.Equal(t, 2, events.handlerCount)
+
+	err = events.ReplaceAll(map[string]interface{}{"a": noop, "b": noop, "c": noop, "d": noop})
+	assert.EqualError(t, err, ErrHandlerLimit.Error())
+}
+
+func TestWithMaxGoroutines(t *testing.T) {
+	events := New(WithMaxGoroutines(4))
+	var running, peak int32
+	assert.NoError(t, events.On("flood", func() {
+		n := atomic.AddInt32(&running, 1)
+		for old := atomic.LoadInt32(&peak); n > old; old = atomic.LoadInt32(&peak) {
+			if atomic.CompareAndSwapInt32(&peak, old, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		atomic.AddInt32(&running, -1)
+	}))
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				assert.NoError(t, events.Send("flood"))
+			}
+		}()
+	}
+	wg.Wait()
+	events.Close()
+	// 发送方阻塞在名额上，同时执行的调用不超过上限
+	assert.True(t, peak <= 4, "peak %d", peak)
+	assert.Equal(t, int64(80), events.Stats().Sent)
+}
+
+func TestWithGoroutineLimitFailFast(t *testing.T) {
+	events := New(WithMaxGoroutines(2), WithGoroutineLimitFailFast())
+	events.Pause()
+	assert.NoError(t, events.On("job", func() {}))
+	assert.NoError(t, events.Send("job"))
+	assert.NoError(t, events.Send("job"))
+	assert.EqualError(t, events.Send("job"), ErrGoroutineLimit.Error())
+	events.Resume()
+	events.Close()
+	assert.Len(t, events.slots, 0)
+}
+
+func TestWithStrictUnknown(t *testing.T) {
+	strict := New(WithStrictUnknown())
+	defer strict.Close()
+	assert.PanicsWithError(t, ErrNotFound.Error()+": missing", func() {
+		_ = strict.Send("missing")
+	})
+	assert.NoError(t, strict.On("known", func() {}))
+	assert.NotPanics(t, func() {
+		assert.NoError(t, strict.Send("known"))
+	})
+
+	// 订阅器内部的发送不受影响
+	assert.NotPanics(t, func() {
+		assert.Equal(t, ErrNotFound, strict.SendRetained("retained", 1))
+		assert.NoError(t, strict.SendAfter(0, "later"))
+	})
+	retained, ok := strict.Retained("retained")
+	assert.True(t, ok)
+	assert.Equal(t, []interface{}{1}, retained)
+
+	lenient := New()
+	defer lenient.Close()
+	assert.Equal(t, ErrNotFound, lenient.Send("missing"))
+}
+
+func TestWithSingleHandlerMode(t *testing.T) {
+	events := New(WithSingleHandlerMode())
+	defer events.Close()
+	assert.NoError(t, events.On("job", func() {}))
+	assert.Equal(t, ErrExists, events.On("job", func() {}))
+	assert.Equal(t, ErrExists, events.Once("job", func() {}))
+	// 多订阅的注册方法不受影响
+	_, err := events.Subscribe("job", func() {})
+	assert.NoError(t, err)
+	assert.NoError(t, events.OnGroup("job", "workers", func() {}))
+}
+
+func TestWithMaxHandlers_Any(t *testing.T) {
+	events := New(WithMaxHandlers(2))
+	defer events.Close()
+	noop := func(string) {}
+
+	// 全量订阅计入总数
+	assert.NoError(t, events.OnAny(noop))
+	assert.NoError(t, events.On("a", func() {}))
+	assert.Equal(t, ErrHandlerLimit, events.OnAny(noop))
+	assert.Equal(t, ErrHandlerLimit, events.On("b", func() {}))
+	assert.Equal(t, ErrHandlerLimit, events.ReplaceAll(map[string]interface{}{"a": func() {}, "b": func() {}}))
+	assert.NoError(t, events.ReplaceAll(map[string]interface{}{"b": func() {}}))
+	assert.Equal(t, 2, events.handlerCount)
+}
@@ -0,0 +1,92 @@
+package eventbus
+
+import "sync/atomic"
+
+// 一次发送的调用顺序:
+//  1. 事件自身的订阅(或兜底订阅)，按注册顺序
+//  2. 匹配的通配订阅，按注册顺序
+//  3. 全量订阅，按注册顺序
+// 全部订阅在同一协程内依次调用。WithExactFirst(false) 把通配订阅移到事件自身的订阅之前，
+// WithFirehoseFirst 把全量订阅移到最前面
+
+// WithFirehoseFirst 全量订阅在事件自身的订阅之前调用，用于审计类订阅需要先于业务处理看到事件的场景
+func WithFirehoseFirst() Option {
+	return func(p *EventBus) {
+		p.firehoseFirst = true
+	}
+}
+
+// WithExactFirst 设置事件自身的订阅与匹配的通配订阅的先后顺序，默认 true，事件自身的订阅先调用
+// false 时通配订阅先调用，用于通配订阅做统一的前置处理的场景
+func WithExactFirst(exactFirst bool) Option {
+	return func(p *EventBus) {
+		p.wildcardFirst = !exactFirst
+	}
+}
+
+// orderedLocked 按调用顺序返回发送 s 的全部订阅，冒泡发送同时写入 s.levels，调用方需持有 mu
+func (p *EventBus) orderedLocked(s *sender, pick groupPick) ([]*event, error) {
+	var handlers []*event
+	var err error
+	if s.bubble {
+		handlers, s.levels, err = p.bubbleLocked(s.key, s.args, pick)
+	} else {
+		handlers, err = p.matchLocked(s.key, s.version, s.args, pick)
+	}
+	if err != nil && err != ErrNotFound {
+		return nil, err
+	}
+	// 全量订阅接收到事件时不再返回 ErrNotFound
+	if anys := p.firehose(s.args); len(anys) > 0 {
+		if p.firehoseFirst {
+			handlers = append(anys[:len(anys):len(anys)], handlers...)
+			if s.levels != nil {
+				s.levels = append(make([]int, len(anys)), s.levels...)
+			}
+		} else {
+			handlers = append(handlers[:len(handlers):len(handlers)], anys...)
+			if s.levels != nil {
+				// 全量订阅不属于任何上级事件，停止冒泡后依旧调用
+				s.levels = append(s.levels, make([]int, len(anys))...)
+			}
+		}
+		err = nil
+	}
+	return handlers, err
+}
+
+// keptLevels 从 all 的层级中取出 kept 对应的部分，kept 是 all 按原顺序的子集
+func keptLevels(all, kept []*event, levels []int) []int {
+	out := make([]int, 0, len(kept))
+	j := 0
+	for i, e := range all {
+		if j < len(kept) && kept[j] == e {
+			out = append(out, levels[i])
+			j++
+		}
+	}
+	return out
+}
+
+// HandlerOrder 返回发送 args 到 eventKey 时将被调用的订阅，按调用顺序排列，不会触发调用
+// 消费组返回下一次发送将轮到的订阅，不影响轮询
+// 错误与 Send 一致，没有订阅返回 ErrNotFound，入参不匹配返回 ErrArgsNotMatch
+func (p *EventBus) HandlerOrder(eventKey string, args ...interface{}) ([]EventInfo, error) {
+	p.mu.RLock()
+	handlers, err := p.orderedLocked(&sender{key: eventKey, args: args}, pickPeek)
+	p.mu.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+	infos := make([]EventInfo, 0, len(handlers))
+	for _, e := range handlers {
+		infos = append(infos, EventInfo{
+			Key:       e.key,
+			ID:        e.id,
+			Once:      e.once,
+			Version:   e.version,
+			CallTimes: atomic.LoadInt32(&e.callTimes),
+		})
+	}
+	return infos, nil
+}
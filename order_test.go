@@ -0,0 +1,95 @@
+package eventbus
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func orderBus(t *testing.T, opts ...Option) (*EventBus, func() []string) {
+	events := New(opts...)
+	var mu sync.Mutex
+	var calls []string
+	record := func(name string) {
+		mu.Lock()
+		calls = append(calls, name)
+		mu.Unlock()
+	}
+	assert.NoError(t, events.OnAny(func(string, int) { record("any1") }))
+	assert.NoError(t, events.OnWildcard("order.*", func(string, int) { record("wild2") }))
+	_, err := events.Subscribe("order.created", func(int) { record("key1") })
+	assert.NoError(t, err)
+	assert.NoError(t, events.OnWildcard("*.created", func(string, int) { record("wild1") }))
+	_, err = events.Subscribe("order.created", func(int) { record("key2") })
+	assert.NoError(t, err)
+	assert.NoError(t, events.OnAny(func(string, int) { record("any2") }))
+	return events, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return calls
+	}
+}
+
+func TestHandlerOrder(t *testing.T) {
+	events, calls := orderBus(t)
+	infos, err := events.HandlerOrder("order.created", 1)
+	assert.NoError(t, err)
+	var keys []string
+	for _, info := range infos {
+		keys = append(keys, info.Key)
+	}
+	assert.Equal(t, []string{"order.created", "order.created", "order.*", "*.created", "any", "any"}, keys)
+
+	assert.NoError(t, events.Send("order.created", 1))
+	events.Close()
+	assert.Equal(t, []string{"key1", "key2", "wild2", "wild1", "any1", "any2"}, calls())
+
+	_, err = events.HandlerOrder("order.created", 1, 2)
+	assert.Equal(t, ErrArgsNotMatch, err)
+}
+
+func TestWithFirehoseFirst(t *testing.T) {
+	events, calls := orderBus(t, WithFirehoseFirst())
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, events.SendSync("order.created", i))
+	}
+	events.Close()
+	want := []string{"any1", "any2", "key1", "key2", "wild2", "wild1"}
+	assert.Equal(t, append(append(append([]string{}, want...), want...), want...), calls())
+}
+
+func TestHandlerOrder_Group(t *testing.T) {
+	events := New()
+	got := make(chan string, 2)
+	assert.NoError(t, events.OnGroup("job", "workers", func() { got <- "a" }))
+	assert.NoError(t, events.OnGroup("job", "workers", func() { got <- "b" }))
+	for i := 0; i < 2; i++ {
+		infos, err := events.HandlerOrder("job")
+		assert.NoError(t, err)
+		assert.Len(t, infos, 1)
+	}
+	// 查询不影响轮询
+	assert.NoError(t, events.SendSync("job"))
+	assert.NoError(t, events.SendSync("job"))
+	events.Close()
+	assert.Equal(t, "a", <-got)
+	assert.Equal(t, "b", <-got)
+}
+
+func TestWithExactFirst(t *testing.T) {
+	for _, exactFirst := range []bool{true, false} {
+		events := New(WithExactFirst(exactFirst))
+		var got []string
+		assert.NoError(t, events.OnWildcard("order.*", func(key string) { got = append(got, "wildcard") }))
+		assert.NoError(t, events.On("order.paid", func() { got = append(got, "exact") }))
+		assert.NoError(t, events.OnWildcard("*.paid", func(key string) { got = append(got, "wildcard2") }))
+		assert.NoError(t, events.SendSync("order.paid"))
+		events.Close()
+		if exactFirst {
+			assert.Equal(t, []string{"exact", "wildcard", "wildcard2"}, got)
+		} else {
+			assert.Equal(t, []string{"wildcard", "wildcard2", "exact"}, got)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package eventbus
+
+import (
+	"bytes"
+	"fmt"
+	"runtime"
+	"sync"
+)
+
+// PanicStack 订阅 panic 时捕获的调用栈，捕获时只记录程序计数器，首次读取时才格式化
+type PanicStack struct {
+	pcs  []uintptr
+	once sync.Once
+	text []byte
+}
+
+// maxPanicFrames 捕获的最大栈帧数
+const maxPanicFrames = 64
+
+// capturePanicStack 在 recover 所在的 defer 中调用，跳过自身和 defer 函数
+func capturePanicStack() *PanicStack {
+	pcs := make([]uintptr, maxPanicFrames)
+	n := runtime.Callers(3, pcs)
+	return &PanicStack{pcs: pcs[:n]}
+}
+
+// Bytes 返回格式化后的调用栈，每一帧输出函数名和 文件:行号
+func (s *PanicStack) Bytes() []byte {
+	s.once.Do(func() {
+		var buf bytes.Buffer
+		frames := runtime.CallersFrames(s.pcs)
+		for {
+			frame, more := frames.Next()
+			fmt.Fprintf(&buf, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+			if !more {
+				break
+			}
+		}
+		s.text = buf.Bytes()
+	})
+	return s.text
+}
+
+func (s *PanicStack) String() string {
+	return string(s.Bytes())
+}
+
+// PanicError 订阅 panic 时返回的错误，保留 recover 得到的值和调用栈
+// errors.Is(err, ErrRuntimePanic) 为 true，可以用 errors.As 取出
+type PanicError struct {
+	Value interface{}
+	Key   string
+	stack *PanicStack
+}
+
+// Stack 返回格式化后的调用栈，首次调用时才格式化
+func (e *PanicError) Stack() []byte {
+	if e.stack == nil {
+		return nil
+	}
+	return e.stack.Bytes()
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("%s: %v", ErrRuntimePanic, e.Value)
+}
+
+func (e *PanicError) Unwrap() error {
+	return ErrRuntimePanic
+}
+
+// WithPanicHook 订阅 panic 时调用 hook，传入事件、recover 得到的值和调用栈
+// 只有发生 panic 时才捕获调用栈，并且在 hook 读取时才格式化，没有 panic 的调用没有额外开销
+func WithPanicHook(hook func(eventKey string, value interface{}, stack *PanicStack)) Option {
+	return func(p *EventBus) {
+		p.panicHook = hook
+	}
+}
+
+// WithSilentPanics 订阅 panic 时不输出日志，调用仍然返回 *PanicError，WithPanicHook 仍然调用
+// 用于测试中验证 panic 的场景，避免日志干扰测试输出
+func WithSilentPanics() Option {
+	return func(p *EventBus) {
+		p.silentPanics = true
+	}
+}
+
+// recovered 处理订阅调用中 recover 得到的值
+func (e *event) recovered(rec interface{}, stack *PanicStack) {
+	if e.bus == nil || !e.bus.silentPanics {
+		e.logger().Printf("[PANIC RECOVER] call %s panic: %s", e.key, rec)
+	}
+	if e.bus != nil && e.bus.panicHook != nil {
+		e.bus.panicHook(e.key, rec, stack)
+	}
+}
@@ -0,0 +1,78 @@
+package eventbus
+
+import (
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func explode() {
+	panic("boom")
+}
+
+func TestEventBus_WithPanicHook(t *testing.T) {
+	type panicked struct {
+		key   string
+		value interface{}
+		stack *PanicStack
+	}
+	got := make(chan panicked, 2)
+	events := New(WithPanicHook(func(eventKey string, value interface{}, stack *PanicStack) {
+		got <- panicked{eventKey, value, stack}
+	}))
+	defer events.Close()
+	assert.NoError(t, events.On("ok", func() {}))
+	assert.NoError(t, events.On("boom", explode))
+
+	assert.NoError(t, events.SendSync("ok"))
+	assert.Len(t, got, 0)
+
+	assert.ErrorIs(t, events.SendSync("boom"), ErrRuntimePanic)
+	p := <-got
+	assert.Equal(t, "boom", p.key)
+	assert.Equal(t, "boom", p.value)
+	if assert.NotNil(t, p.stack) {
+		// 调用栈包含 panic 所在的函数
+		assert.Contains(t, p.stack.String(), "eventbus.explode")
+		assert.Equal(t, p.stack.Bytes(), p.stack.Bytes())
+	}
+}
+
+func TestEventBus_PanicError(t *testing.T) {
+	events := New(WithSilentPanics())
+	defer events.Close()
+	assert.NoError(t, events.On("boom", explode))
+
+	err := events.SendSync("boom")
+	assert.ErrorIs(t, err, ErrRuntimePanic)
+	var perr *PanicError
+	if assert.ErrorAs(t, err, &perr) {
+		assert.Equal(t, "boom", perr.Value)
+		assert.Equal(t, "boom", perr.Key)
+		// 读取之前不格式化调用栈
+		assert.Nil(t, perr.stack.text)
+		assert.Contains(t, string(perr.Stack()), "eventbus.explode")
+	}
+	assert.EqualError(t, err, ErrRuntimePanic.Error()+": boom")
+}
+
+func TestEventBus_WithSilentPanics(t *testing.T) {
+	logger := &recordLogger{}
+	var hooked int32
+	events := New(WithLogger(logger), WithSilentPanics(), WithPanicHook(func(string, interface{}, *PanicStack) {
+		atomic.AddInt32(&hooked, 1)
+	}))
+	defer events.Close()
+	assert.NoError(t, events.On("boom", explode))
+	assert.ErrorIs(t, events.SendSync("boom"), ErrRuntimePanic)
+	assert.Empty(t, logger.Lines())
+	assert.Equal(t, int32(1), hooked)
+
+	// 默认输出日志
+	loud := New(WithLogger(logger))
+	defer loud.Close()
+	assert.NoError(t, loud.On("boom", explode))
+	assert.ErrorIs(t, loud.SendSync("boom"), ErrRuntimePanic)
+	assert.Len(t, logger.Lines(), 1)
+}
@@ -0,0 +1,97 @@
+package eventbus
+
+// a pool 执行发送的工作协程
+type pool struct {
+	// chans 共享模式下只有一个全部协程共享的 channel，粘滞模式下每个协程一个
+	chans  []chan *sender
+	sticky bool
+}
+
+// WithWorkers 使用 n 个固定的工作协程执行发送，代替每个发送一个新协程
+func WithWorkers(n int) Option {
+	return func(p *EventBus) {
+		p.workers = n
+	}
+}
+
+// WithStickyWorkers 配合 WithWorkers 使用，同一事件的发送总是交给同一个工作协程，
+// 同一事件依次执行，不同事件分散到各个工作协程并发执行，不需要全局锁
+func WithStickyWorkers() Option {
+	return func(p *EventBus) {
+		p.stickyWorkers = true
+	}
+}
+
+func newPool(n int, sticky bool, execute func(s *sender)) *pool {
+	w := &pool{sticky: sticky}
+	if sticky {
+		w.chans = make([]chan *sender, n)
+		for i := range w.chans {
+			// 中转协程缓存该工作协程的发送，执行中的慢事件不会阻塞 Loop
+			w.chans[i] = make(chan *sender, 1)
+			out := make(chan *sender)
+			go w.relay(w.chans[i], out)
+			go w.work(out, execute)
+		}
+		return w
+	}
+	ch := make(chan *sender, n)
+	w.chans = []chan *sender{ch}
+	for i := 0; i < n; i++ {
+		go w.work(ch, execute)
+	}
+	return w
+}
+
+// worker 返回执行发送的 channel，粘滞模式下按事件的摘要选择
+func (w *pool) worker(eventKey string) chan *sender {
+	if !w.sticky {
+		return w.chans[0]
+	}
+	return w.chans[keyHash(eventKey)%uint32(len(w.chans))]
+}
+
+// submit 交给工作协程执行，共享模式下协程都在忙时阻塞，粘滞模式下排入该工作协程的队列
+func (w *pool) submit(s *sender) {
+	w.worker(s.key) <- s
+}
+
+// relay 依次把 in 中的发送转交给 out，out 忙时先排队，in 关闭并转交完后关闭 out
+func (w *pool) relay(in, out chan *sender) {
+	var pending []*sender
+	for in != nil || len(pending) > 0 {
+		var next chan *sender
+		var s *sender
+		if len(pending) > 0 {
+			next, s = out, pending[0]
+		}
+		select {
+		case r, ok := <-in:
+			if !ok {
+				in = nil
+				continue
+			}
+			pending = append(pending, r)
+		case next <- s:
+			pending[0] = nil
+			pending = pending[1:]
+		}
+	}
+	close(out)
+}
+
+func (w *pool) work(ch chan *sender, execute func(s *sender)) {
+	for s := range ch {
+		execute(s)
+	}
+}
+
+// stop 全部发送提交之后关闭，工作协程执行完剩余的发送后退出
+func (w *pool) stop() {
+	if w == nil {
+		return
+	}
+	for _, ch := range w.chans {
+		close(ch)
+	}
+}
@@ -0,0 +1,107 @@
+package eventbus
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithWorkers(t *testing.T) {
+	events := New(WithWorkers(2))
+	var running, peak int32
+	assert.NoError(t, events.On("job", func() {
+		n := atomic.AddInt32(&running, 1)
+		for old := atomic.LoadInt32(&peak); n > old; old = atomic.LoadInt32(&peak) {
+			if atomic.CompareAndSwapInt32(&peak, old, n) {
+				break
+			}
+		}
+		time.Sleep(2 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+	}))
+	for i := 0; i < 10; i++ {
+		assert.NoError(t, events.Send("job"))
+	}
+	events.Close()
+	assert.Equal(t, int32(2), peak)
+	assert.Equal(t, int32(10), events.handlers("job")[0].callTimes)
+}
+
+func TestWithStickyWorkers(t *testing.T) {
+	const workers = 4
+	events := New(WithWorkers(workers), WithStickyWorkers())
+	// 找到落在不同工作协程上的两个事件
+	a, b := "a", "b"
+	for i := 0; stripe(a)%workers == stripe(b)%workers; i++ {
+		b = string(rune('b' + i))
+	}
+	peaks := map[string]*int32{a: new(int32), b: new(int32)}
+	var total, totalPeak int32
+	for key, peak := range peaks {
+		var running int32
+		peak := peak
+		assert.NoError(t, events.On(key, func() {
+			for _, c := range []struct{ running, peak *int32 }{{&running, peak}, {&total, &totalPeak}} {
+				n := atomic.AddInt32(c.running, 1)
+				for old := atomic.LoadInt32(c.peak); n > old; old = atomic.LoadInt32(c.peak) {
+					if atomic.CompareAndSwapInt32(c.peak, old, n) {
+						break
+					}
+				}
+			}
+			time.Sleep(2 * time.Millisecond)
+			atomic.AddInt32(&running, -1)
+			atomic.AddInt32(&total, -1)
+		}))
+	}
+	for i := 0; i < 10; i++ {
+		assert.NoError(t, events.Send(a))
+		assert.NoError(t, events.Send(b))
+	}
+	events.Close()
+
+	// 同一事件在同一个工作协程中依次执行，不同事件并发
+	assert.Equal(t, int32(1), *peaks[a])
+	assert.Equal(t, int32(1), *peaks[b])
+	assert.Equal(t, int32(2), totalPeak)
+}
+
+func TestPool_StickyBeyondStripes(t *testing.T) {
+	w := newPool(128, true, func(*sender) {})
+	defer w.stop()
+	used := map[chan *sender]bool{}
+	for i := 0; i < 4096; i++ {
+		used[w.worker(fmt.Sprintf("key.%d", i))] = true
+	}
+	// 超过 64 个的工作协程同样会被使用
+	assert.True(t, len(used) > lockStripes, "used %d workers", len(used))
+}
+
+func TestWithStickyWorkersBlockedKey(t *testing.T) {
+	const workers = 2
+	events := New(WithWorkers(workers), WithStickyWorkers())
+	a, b := "a", "b"
+	for i := 0; keyHash(a)%workers == keyHash(b)%workers; i++ {
+		b = string(rune('b' + i))
+	}
+	block := make(chan struct{})
+	assert.NoError(t, events.On(a, func() { <-block }))
+	done := make(chan struct{})
+	assert.NoError(t, events.On(b, func() { close(done) }))
+	// a 的工作协程被阻塞，后续 a 的发送排队，不影响 b
+	for i := 0; i < 5; i++ {
+		assert.NoError(t, events.Send(a))
+	}
+	assert.NoError(t, events.Send(b))
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("blocked key stalled other keys")
+	}
+	close(block)
+	events.Close()
+	assert.Equal(t, int32(5), events.handlers(a)[0].callTimes)
+}
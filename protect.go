@@ -0,0 +1,108 @@
+package eventbus
+
+import (
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// selfPackage 本包的导入路径，查找注册方时跳过
+var selfPackage = reflect.TypeOf(EventBus{}).PkgPath()
+
+// WithProtectedPrefix 保护以 prefix 开头的事件，只有 allowedPkgs 中的包(导入路径)可以注册订阅，
+// 其他包调用 On/Once/Subscribe/ReplaceAll 返回 ErrUnauthorized。用于插件架构中限制插件订阅系统事件
+// 能够匹配受保护事件的通配订阅、全量订阅 OnAny 和兜底订阅 OnFallback 同样需要授权
+// 注册方通过调用栈识别，本包内的调用会被跳过
+func WithProtectedPrefix(prefix string, allowedPkgs ...string) Option {
+	return func(p *EventBus) {
+		if p.protected == nil {
+			p.protected = make(map[string]map[string]bool)
+		}
+		allowed := make(map[string]bool, len(allowedPkgs))
+		for _, pkg := range allowedPkgs {
+			allowed[pkg] = true
+		}
+		p.protected[prefix] = allowed
+	}
+}
+
+// authorize 校验注册方是否可以注册 eventKey，通配事件可能匹配受保护的事件时同样校验
+func (p *EventBus) authorize(eventKey string) error {
+	return p.authorizeIf(func(prefix string) bool {
+		return strings.HasPrefix(eventKey, prefix) || (isPattern(eventKey) && patternReaches(eventKey, prefix))
+	})
+}
+
+// authorizeAny 校验注册方是否可以接收全部事件，需要对每个受保护的前缀都有授权
+func (p *EventBus) authorizeAny() error {
+	return p.authorizeIf(func(string) bool { return true })
+}
+
+// authorizeIf 对 covers 返回 true 的受保护前缀校验注册方
+func (p *EventBus) authorizeIf(covers func(prefix string) bool) error {
+	caller := ""
+	for prefix, allowed := range p.protected {
+		if !covers(prefix) {
+			continue
+		}
+		if caller == "" {
+			caller = p.callerPackage()
+		}
+		if !allowed[caller] {
+			return ErrUnauthorized
+		}
+	}
+	return nil
+}
+
+// patternReaches 判断通配事件 pattern 是否可能匹配以 prefix 开头的事件
+func patternReaches(pattern, prefix string) bool {
+	segs := strings.Split(pattern, ".")
+	want := strings.Split(prefix, ".")
+	// prefix 的最后一段可能只是某一段的开头
+	for i, w := range want {
+		if i >= len(segs) {
+			return false
+		}
+		seg := segs[i]
+		if seg == "*" {
+			continue
+		}
+		if i == len(want)-1 {
+			return strings.HasPrefix(seg, w)
+		}
+		if seg != w {
+			return false
+		}
+	}
+	return true
+}
+
+// callerPackage 返回调用栈中第一个不属于本包的函数所在的包
+func callerPackage() string {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if pkg := funcPackage(frame.Function); pkg != selfPackage {
+			return pkg
+		}
+		if !more {
+			return ""
+		}
+	}
+}
+
+// funcPackage 从完整的函数名中解析包的导入路径，
+// 如 github.com/luw2007/eventbus.(*EventBus).On 返回 github.com/luw2007/eventbus
+func funcPackage(name string) string {
+	slash := strings.LastIndexByte(name, '/')
+	if slash < 0 {
+		slash = 0
+	}
+	if dot := strings.IndexByte(name[slash:], '.'); dot >= 0 {
+		return name[:slash+dot]
+	}
+	return name
+}
@@ -0,0 +1,64 @@
+package eventbus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithProtectedPrefix(t *testing.T) {
+	events := New(WithProtectedPrefix("sys.", "github.com/app/core"))
+	defer events.Close()
+	noop := func() {}
+
+	// 模拟不同包的注册方
+	events.callerPackage = func() string { return "github.com/app/core" }
+	assert.NoError(t, events.On("sys.boot", noop))
+
+	events.callerPackage = func() string { return "github.com/app/plugin" }
+	err := events.On("sys.shutdown", noop)
+	assert.EqualError(t, err, ErrUnauthorized.Error())
+	_, err = events.Subscribe("sys.boot", noop)
+	assert.EqualError(t, err, ErrUnauthorized.Error())
+	// 未受保护的事件不受限制
+	assert.NoError(t, events.On("plugin.ready", noop))
+}
+
+func TestCallerPackage(t *testing.T) {
+	// 本包内的调用被跳过，测试由 testing 包调用
+	assert.Equal(t, "testing", callerPackage())
+
+	assert.Equal(t, "github.com/luw2007/eventbus", funcPackage("github.com/luw2007/eventbus.(*EventBus).On"))
+	assert.Equal(t, "github.com/app/core", funcPackage("github.com/app/core.init.func1"))
+	assert.Equal(t, "main", funcPackage("main.main"))
+}
+
+func TestWithProtectedPrefix_Observers(t *testing.T) {
+	events := New(WithProtectedPrefix("sys.", "github.com/app/core"))
+	defer events.Close()
+	noop := func(string) {}
+
+	events.callerPackage = func() string { return "github.com/app/plugin" }
+	assert.Equal(t, ErrUnauthorized, events.OnWildcard("*.*", noop))
+	assert.Equal(t, ErrUnauthorized, events.OnWildcard("sys.*", noop))
+	assert.Equal(t, ErrUnauthorized, events.OnAny(noop))
+	assert.Equal(t, ErrUnauthorized, events.OnFallback(noop))
+	assert.Equal(t, ErrUnauthorized, events.ReplaceAll(map[string]interface{}{"sys.boot": func() {}}))
+	// 不可能匹配受保护事件的通配订阅不受限制
+	assert.NoError(t, events.OnWildcard("plugin.*", noop))
+	assert.NoError(t, events.OnWildcard("*", noop))
+
+	events.callerPackage = func() string { return "github.com/app/core" }
+	assert.NoError(t, events.OnAny(noop))
+	assert.NoError(t, events.OnWildcard("*.*", noop))
+}
+
+func TestPatternReaches(t *testing.T) {
+	assert.True(t, patternReaches("*.*", "sys."))
+	assert.True(t, patternReaches("sys.*", "sys."))
+	assert.False(t, patternReaches("*", "sys."))
+	assert.False(t, patternReaches("app.*", "sys."))
+	assert.True(t, patternReaches("*.boot", "sys"))
+	assert.True(t, patternReaches("sys.*", "sys.bo"))
+	assert.False(t, patternReaches("sys.*.x", "sys.boot.y"))
+}
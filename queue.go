@@ -0,0 +1,230 @@
+package eventbus
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// a queue 待调用的发送队列，按有效优先级出队，有效优先级相同时先进先出
+// 有效优先级 = 优先级 + 排队秒数 * aging，持续的高优先级负载下低优先级事件最终也会被调用
+type queue struct {
+	mu    sync.Mutex
+	items []*sender
+	aging float64
+	now   func() time.Time
+	// ready 有新数据入队时发出信号
+	ready chan struct{}
+	// closed Loop 开始最后一次取出后不再接受新的发送
+	closed bool
+}
+
+// QueuedEvent 队列中等待调用的事件
+type QueuedEvent struct {
+	ID         uint64
+	Key        string
+	Args       []interface{}
+	Priority   int
+	EnqueuedAt time.Time
+}
+
+func newQueue() *queue {
+	return &queue{
+		now:   time.Now,
+		ready: make(chan struct{}, 1),
+	}
+}
+
+// push 放入一次发送，队列已经关闭时返回 false
+func (q *queue) push(s *sender) bool {
+	q.mu.Lock()
+	if q.closed {
+		q.mu.Unlock()
+		return false
+	}
+	s.enqueued = q.now()
+	q.items = append(q.items, s)
+	q.mu.Unlock()
+	q.signal()
+	return true
+}
+
+// close 关闭队列，已经入队的发送依旧可以取出
+func (q *queue) close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.closed = true
+}
+
+// signal 通知消费者队列中有数据
+func (q *queue) signal() {
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+}
+
+// snapshot 按入队顺序返回队列中事件的副本
+func (q *queue) snapshot() []QueuedEvent {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	events := make([]QueuedEvent, len(q.items))
+	for i, s := range q.items {
+		events[i] = QueuedEvent{
+			ID:         s.id,
+			Key:        s.key,
+			Args:       s.args,
+			Priority:   s.priority,
+			EnqueuedAt: s.enqueued,
+		}
+	}
+	return events
+}
+
+func (q *queue) len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.items)
+}
+
+// pop 取出有效优先级最高的发送，队列为空时返回 false
+func (q *queue) pop() (*sender, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.items) == 0 {
+		return nil, false
+	}
+	best := 0
+	now := q.now()
+	for i := 1; i < len(q.items); i++ {
+		if q.effective(q.items[i], now) > q.effective(q.items[best], now) {
+			best = i
+		}
+	}
+	s := q.items[best]
+	q.delete(best)
+	if s.dequeued != nil {
+		close(s.dequeued)
+	}
+	return s, true
+}
+
+// takeAll 按入队顺序取出队列中的全部发送
+func (q *queue) takeAll() []*sender {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	items := q.items
+	q.items = nil
+	for _, s := range items {
+		if s.dequeued != nil {
+			close(s.dequeued)
+		}
+	}
+	return items
+}
+
+// remove 从队列中移除尚未出队的 s，s 已经出队时返回 false
+func (q *queue) remove(s *sender) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i, item := range q.items {
+		if item == s {
+			q.delete(i)
+			return true
+		}
+	}
+	return false
+}
+
+// delete 删除第 i 个元素，调用方需持有 mu
+func (q *queue) delete(i int) {
+	copy(q.items[i:], q.items[i+1:])
+	q.items[len(q.items)-1] = nil
+	q.items = q.items[:len(q.items)-1]
+}
+
+// effective 计算排队中的发送在 now 时刻的有效优先级
+func (q *queue) effective(s *sender, now time.Time) float64 {
+	return float64(s.priority) + q.aging*now.Sub(s.enqueued).Seconds()
+}
+
+// SendPriority 以指定优先级调用事件，队列中优先级高的事件先被调用，Send 的优先级为 0
+func (p *EventBus) SendPriority(eventKey string, priority int, args ...interface{}) error {
+	return p.send(&sender{key: eventKey, args: args, priority: priority})
+}
+
+// WithPriorityInheritance 订阅中使用注入的 context 调用 SendCtx/SendSyncCtx 时，新事件继承触发它的事件的优先级，
+// 高优先级的处理流程在级联发送中保持高优先级
+func WithPriorityInheritance() Option {
+	return func(p *EventBus) {
+		p.inheritPriority = true
+	}
+}
+
+// inherit 开启 WithPriorityInheritance 时使用 s.ctx 所属发送的优先级
+func (p *EventBus) inherit(s *sender) {
+	if !p.inheritPriority {
+		return
+	}
+	if chain := CausationChain(s.ctx); len(chain) > 0 {
+		s.priority = chain[len(chain)-1].Priority
+	}
+}
+
+// Pause 暂停调用，之后的发送依旧放入队列，Resume 后继续调用
+func (p *EventBus) Pause() {
+	atomic.StoreInt32(&p.paused, 1)
+}
+
+// Resume 恢复调用暂停期间积压的事件
+func (p *EventBus) Resume() {
+	atomic.StoreInt32(&p.paused, 0)
+	p.queue.signal()
+}
+
+func (p *EventBus) isPaused() bool {
+	return atomic.LoadInt32(&p.paused) == 1
+}
+
+// PausePolicy 暂停期间异步发送的处理方式
+type PausePolicy int
+
+const (
+	// PauseBuffer 放入队列，Resume 后调用，默认的处理方式
+	PauseBuffer PausePolicy = iota
+	// PauseDrop 直接丢弃，发送返回 nil
+	PauseDrop
+	// PauseReject 拒绝发送，返回 ErrPaused
+	PauseReject
+)
+
+// WithPausePolicy 设置暂停期间异步发送的处理方式，同步发送不经过队列，不受暂停影响
+func WithPausePolicy(policy PausePolicy) Option {
+	return func(p *EventBus) {
+		p.pausePolicy = policy
+	}
+}
+
+// admitPaused 按 PausePolicy 处理暂停期间的异步发送，返回 false 时不再继续发送
+func (p *EventBus) admitPaused(s *sender) (bool, error) {
+	if s.sync != nil || p.pausePolicy == PauseBuffer || !p.isPaused() {
+		return true, nil
+	}
+	if p.pausePolicy == PauseReject {
+		return false, ErrPaused
+	}
+	return false, nil
+}
+
+// PendingSnapshot 按入队顺序返回队列中尚未调用的事件副本，用于排查积压
+func (p *EventBus) PendingSnapshot() []QueuedEvent {
+	return p.queue.snapshot()
+}
+
+// WithPriorityAging 设置优先级老化速率，事件每排队一秒有效优先级增加 rate，
+// 防止持续的高优先级负载饿死低优先级事件
+func WithPriorityAging(rate float64) Option {
+	return func(p *EventBus) {
+		p.queue.aging = rate
+	}
+}
@@ -0,0 +1,179 @@
+package eventbus
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueue_Priority(t *testing.T) {
+	q := newQueue()
+	q.push(&sender{key: "low1"})
+	q.push(&sender{key: "high", priority: 1})
+	q.push(&sender{key: "low2"})
+
+	var keys []string
+	for s, ok := q.pop(); ok; s, ok = q.pop() {
+		keys = append(keys, s.key)
+	}
+	// 优先级高的先出队，相同优先级先进先出
+	assert.Equal(t, []string{"high", "low1", "low2"}, keys)
+}
+
+// flood 放入一个低优先级事件后持续放入高优先级事件，返回低优先级事件出队时已出队的数量
+func flood(q *queue, rounds int) int {
+	now := time.Now()
+	q.now = func() time.Time { return now }
+	q.push(&sender{key: "low"})
+	for i := 0; i < rounds; i++ {
+		now = now.Add(time.Second)
+		q.push(&sender{key: "high", priority: 10})
+		if s, _ := q.pop(); s.key == "low" {
+			return i
+		}
+	}
+	return -1
+}
+
+func TestQueue_Aging(t *testing.T) {
+	// 没有老化时低优先级事件一直得不到调用
+	assert.Equal(t, -1, flood(newQueue(), 100))
+
+	q := newQueue()
+	q.aging = 1
+	n := flood(q, 100)
+	assert.True(t, n >= 0 && n <= 11, "low priority dispatched after %d", n)
+}
+
+func TestEventBus_SendPriority(t *testing.T) {
+	events := New(WithPriorityAging(1))
+	defer events.Close()
+	assert.Equal(t, float64(1), events.queue.aging)
+
+	got := make(chan int, 1)
+	events.On("add", func(a int) {
+		got <- a
+	})
+	err := events.SendPriority("add", 5, 1)
+	assert.NoError(t, err)
+	select {
+	case a := <-got:
+		assert.Equal(t, 1, a)
+	case <-time.After(time.Second):
+		t.Fatal("event not received")
+	}
+	err = events.SendPriority("missing", 5)
+	assert.EqualError(t, err, ErrNotFound.Error())
+}
+
+func TestEventBus_PendingSnapshot(t *testing.T) {
+	events := New()
+	defer events.Close()
+	got := make(chan int, 2)
+	events.On("add", func(a int) {
+		got <- a
+	})
+
+	events.Pause()
+	events.Send("add", 1)
+	events.SendPriority("add", 1, 2)
+	pending := events.PendingSnapshot()
+	assert.Len(t, pending, 2)
+	assert.Equal(t, "add", pending[0].Key)
+	assert.Equal(t, []interface{}{1}, pending[0].Args)
+	assert.Equal(t, []interface{}{2}, pending[1].Args)
+	assert.Equal(t, 1, pending[1].Priority)
+	assert.True(t, pending[0].ID < pending[1].ID)
+	select {
+	case a := <-got:
+		t.Fatalf("event %d dispatched while paused", a)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	// 恢复后调用积压的事件
+	events.Resume()
+	var received []int
+	for len(received) < 2 {
+		select {
+		case a := <-got:
+			received = append(received, a)
+		case <-time.After(time.Second):
+			t.Fatal("event not received")
+		}
+	}
+	assert.ElementsMatch(t, []int{1, 2}, received)
+	assert.Empty(t, events.PendingSnapshot())
+}
+
+func TestWithPriorityInheritance(t *testing.T) {
+	for _, inherit := range []bool{true, false} {
+		var opts []Option
+		if inherit {
+			opts = append(opts, WithPriorityInheritance())
+		}
+		events := New(opts...)
+		sent := make(chan error, 1)
+		assert.NoError(t, events.On("parent", func(ctx context.Context) {
+			// 暂停后子事件留在队列中，便于读取优先级
+			events.Pause()
+			sent <- events.SendCtx(ctx, "child")
+		}))
+		assert.NoError(t, events.On("child", func() {}))
+		assert.NoError(t, events.SendPriority("parent", 7))
+		assert.NoError(t, <-sent)
+		pending := events.PendingSnapshot()
+		assert.Len(t, pending, 1)
+		want := 0
+		if inherit {
+			want = 7
+		}
+		assert.Equal(t, want, pending[0].Priority)
+		events.Resume()
+		events.Close()
+	}
+}
+
+func TestEventBus_WithPausePolicy(t *testing.T) {
+	for name, tc := range map[string]struct {
+		policy  PausePolicy
+		err     error
+		pending int
+	}{
+		"buffer": {PauseBuffer, nil, 1},
+		"drop":   {PauseDrop, nil, 0},
+		"reject": {PauseReject, ErrPaused, 0},
+	} {
+		t.Run(name, func(t *testing.T) {
+			events := New(WithPausePolicy(tc.policy))
+			defer events.Close()
+			got := make(chan int, 2)
+			assert.NoError(t, events.On("add", func(a int) { got <- a }))
+			events.Pause()
+			assert.Equal(t, tc.err, events.Send("add", 1))
+			assert.Len(t, events.PendingSnapshot(), tc.pending)
+			// 同步发送不受暂停影响
+			assert.NoError(t, events.SendSync("add", 2))
+			assert.Equal(t, 2, <-got)
+			events.Resume()
+			assert.NoError(t, events.Send("add", 3))
+			if tc.pending > 0 {
+				assert.ElementsMatch(t, []int{1, 3}, []int{<-got, <-got})
+			} else {
+				assert.Equal(t, 3, <-got)
+			}
+		})
+	}
+}
+
+func TestQueue_Close(t *testing.T) {
+	q := newQueue()
+	assert.True(t, q.push(&sender{key: "a"}))
+	q.close()
+	assert.False(t, q.push(&sender{key: "b"}))
+	// 关闭前入队的发送依旧可以取出
+	s, ok := q.pop()
+	assert.True(t, ok)
+	assert.Equal(t, "a", s.key)
+}
@@ -0,0 +1,7 @@
+package eventbus
+
+// OnRaw 注册直接接收全部入参的订阅，调用时不做反射和参数个数校验，适合入参较多的高频事件
+// 类型断言由调用方法自行负责，返回的错误作为执行结果
+func (p *EventBus) OnRaw(eventKey string, call func(args []interface{}) error) error {
+	return p.on(&event{key: eventKey, call: call, raw: call}, true)
+}
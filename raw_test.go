@@ -0,0 +1,55 @@
+package eventbus
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventBus_OnRaw(t *testing.T) {
+	events := New(WithSingleHandlerMode())
+	done := make(chan []interface{}, 2)
+	assert.NoError(t, events.OnRaw("row", func(args []interface{}) error {
+		done <- args
+		return nil
+	}))
+	assert.EqualError(t, events.OnRaw("row", func([]interface{}) error { return nil }), ErrExists.Error())
+	// 任意个数的入参
+	assert.NoError(t, events.Send("row", 1, "a", 2.5))
+	assert.NoError(t, events.Send("row"))
+	events.Close()
+	assert.ElementsMatch(t, [][]interface{}{{1, "a", 2.5}, nil}, [][]interface{}{<-done, <-done})
+
+	failed := errors.New("failed")
+	e := &event{key: "raw", raw: func([]interface{}) error { return failed }}
+	assert.NoError(t, New().setup(e))
+	assert.Equal(t, failed, e.Call(nil))
+}
+
+var benchArgs = []interface{}{1, "a", 2.5, true, int64(3), "b"}
+
+func BenchmarkEvent_CallReflect(b *testing.B) {
+	events := New()
+	defer events.Close()
+	events.On("row", func(a int, b string, c float64, d bool, e int64, f string) error { return nil })
+	e := events.handlers("row")[0]
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		e.Call(benchArgs)
+	}
+}
+
+func BenchmarkEvent_CallRaw(b *testing.B) {
+	events := New()
+	defer events.Close()
+	events.OnRaw("row", func(args []interface{}) error {
+		_, _ = args[0].(int), args[1].(string)
+		return nil
+	})
+	e := events.handlers("row")[0]
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		e.Call(benchArgs)
+	}
+}
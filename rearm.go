@@ -0,0 +1,20 @@
+package eventbus
+
+import "sync/atomic"
+
+// OnFirstAfterReset 注册订阅，只在首次发送时调用，之后保持休眠但不注销，ResetOnce 之后再次调用一次
+// 用于边沿触发的状态变化，同一事件允许注册多个订阅
+func (p *EventBus) OnFirstAfterReset(eventKey string, call interface{}) error {
+	return p.on(&event{key: eventKey, call: call, rearm: true}, false)
+}
+
+// ResetOnce 重新启用事件下全部 OnFirstAfterReset 注册的订阅
+func (p *EventBus) ResetOnce(eventKey string) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	for _, e := range p.handlers(eventKey) {
+		if e.rearm {
+			atomic.StoreInt32(&e.fired, 0)
+		}
+	}
+}
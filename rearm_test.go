@@ -0,0 +1,23 @@
+package eventbus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventBus_OnFirstAfterReset(t *testing.T) {
+	events := New()
+	defer events.Close()
+	called := make(chan struct{}, 4)
+	assert.NoError(t, events.OnFirstAfterReset("online", func() { called <- struct{}{} }))
+
+	assert.NoError(t, events.SendSync("online"))
+	assert.Equal(t, ErrAlreadyFired, events.SendSync("online"))
+	events.ResetOnce("online")
+	assert.NoError(t, events.SendSync("online"))
+	assert.Equal(t, ErrAlreadyFired, events.SendSync("online"))
+	assert.Len(t, called, 2)
+	// 休眠期间仍然保持注册
+	assert.Equal(t, []string{"online"}, events.Keys())
+}
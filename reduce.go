@@ -0,0 +1,45 @@
+package eventbus
+
+import (
+	"reflect"
+	"sync/atomic"
+)
+
+// Reduce 同步调用事件的全部订阅，从 seed 开始按注册顺序用 reducer 合并每个订阅的返回值
+// 订阅必须有且只有一个返回值，否则返回 ErrReturnNotMatch 且不会调用任何订阅
+// 订阅执行出错时返回已经合并的结果和错误
+func (p *EventBus) Reduce(eventKey string, seed interface{}, reducer func(acc, handlerResult interface{}) interface{}, args ...interface{}) (interface{}, error) {
+	handlers, err := p.match(eventKey, args, pickNext)
+	if err != nil {
+		return seed, err
+	}
+	for _, e := range handlers {
+		if e.isRaw() || e.lazy != nil || reflect.TypeOf(e.call).NumOut() != 1 {
+			return seed, ErrReturnNotMatch
+		}
+	}
+	if handlers, err = claimOnce(handlers); err != nil {
+		return seed, err
+	}
+	p.removeOnce(handlers)
+	// 与同步发送一样在当前协程中调用，订阅收到实际发送的事件并遵守 OnSerialized 的事件锁
+	s := &sender{key: eventKey, args: args, sync: &syncState{depth: 1, held: map[*serialMutex]bool{}}}
+	s.id = atomic.AddUint64(&p.sendSeq, 1)
+	d := &dispatch{id: s.id, key: eventKey, prop: &Propagation{}, sync: s.sync}
+	acc := seed
+	for _, e := range handlers {
+		if e.ready != nil {
+			<-e.ready
+		}
+		var out []reflect.Value
+		err := s.serialize(e, func() (err error) {
+			out, err = e.invoke(d, args, false)
+			return err
+		})
+		if err != nil {
+			return acc, err
+		}
+		acc = reducer(acc, out[0].Interface())
+	}
+	return acc, nil
+}
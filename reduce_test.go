@@ -0,0 +1,87 @@
+package eventbus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func sum(acc, result interface{}) interface{} {
+	return acc.(int) + result.(int)
+}
+
+func TestEventBus_Reduce(t *testing.T) {
+	events := New()
+	defer events.Close()
+	for i := 1; i <= 3; i++ {
+		i := i
+		events.Subscribe("score", func(base int) int {
+			return base * i
+		})
+	}
+
+	total, err := events.Reduce("score", 0, sum, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 60, total)
+
+	_, err = events.Reduce("score", 0, sum)
+	assert.EqualError(t, err, ErrArgsNotMatch.Error())
+	_, err = events.Reduce("missing", 0, sum)
+	assert.EqualError(t, err, ErrNotFound.Error())
+
+	// 没有返回值的订阅
+	events.Subscribe("score", func(base int) {})
+	total, err = events.Reduce("score", 0, sum, 10)
+	assert.EqualError(t, err, ErrReturnNotMatch.Error())
+	assert.Equal(t, 0, total)
+}
+
+func TestEventBus_ReducePanic(t *testing.T) {
+	events := New()
+	defer events.Close()
+	events.Subscribe("score", func(base int) int { return base })
+	events.Subscribe("score", func(base int) int { panic("raise") })
+
+	total, err := events.Reduce("score", 0, sum, 10)
+	assert.ErrorIs(t, err, ErrRuntimePanic)
+	assert.Equal(t, 10, total)
+}
+
+func TestEventBus_ReduceKey(t *testing.T) {
+	events := New()
+	defer events.Close()
+	assert.NoError(t, events.OnWildcard("order.*", func(key string, a int) string { return key }))
+	acc, err := events.Reduce("order.created", "", func(acc, r interface{}) interface{} {
+		return acc.(string) + r.(string)
+	}, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "order.created", acc)
+
+	assert.NoError(t, events.OnFallback(func(key string, args ...interface{}) string { return key }))
+	acc, err = events.Reduce("unknown", "", func(acc, r interface{}) interface{} {
+		return acc.(string) + r.(string)
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "unknown", acc)
+}
+
+func TestEventBus_ReduceSerialized(t *testing.T) {
+	events := New()
+	defer events.Close()
+	assert.NoError(t, events.OnSerialized("count", func() int { return 1 }))
+	l := events.serialLock("count")
+	l.Lock()
+	done := make(chan interface{})
+	go func() {
+		acc, _ := events.Reduce("count", 0, sum)
+		done <- acc
+	}()
+	select {
+	case <-done:
+		t.Fatal("Reduce ignored the serialized lock")
+	case <-time.After(20 * time.Millisecond):
+	}
+	l.Unlock()
+	assert.Equal(t, 1, <-done)
+}
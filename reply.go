@@ -0,0 +1,79 @@
+package eventbus
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// defaultReplyTimeout RequestAsync 默认等待应答的时间
+const defaultReplyTimeout = 30 * time.Second
+
+type replyToKey struct{}
+
+// replyTo 请求携带的应答地址
+type replyTo struct {
+	bus *EventBus
+	key string
+}
+
+// WithReplyTimeout 设置 RequestAsync 等待应答的时间，超时后应答订阅被移除，默认 30 秒
+func WithReplyTimeout(d time.Duration) Option {
+	return func(p *EventBus) {
+		p.replyTimeout = d
+	}
+}
+
+// RequestAsync 发送携带应答地址的请求，不阻塞调用方。订阅通过注入的 context 调用 Reply 应答，
+// 应答的入参写入 reply 后关闭；超时或请求发送失败时 reply 直接关闭。应答订阅在应答一次或超时后移除
+func (p *EventBus) RequestAsync(eventKey string, args ...interface{}) (replyKey string, reply <-chan []interface{}) {
+	replyKey = "reply." + strconv.FormatUint(atomic.AddUint64(&p.replySeq, 1), 10)
+	ch := make(chan []interface{}, 1)
+	var (
+		once  sync.Once
+		timer Timer
+	)
+	finish := func(values []interface{}) {
+		once.Do(func() {
+			if timer != nil {
+				timer.Stop()
+			}
+			if values != nil {
+				ch <- values
+			}
+			close(ch)
+		})
+	}
+	e := &event{key: replyKey, once: true, raw: func(values []interface{}) error {
+		if values == nil {
+			values = []interface{}{}
+		}
+		finish(values)
+		return nil
+	}}
+	if err := p.on(e, false); err != nil {
+		finish(nil)
+		return replyKey, ch
+	}
+	cleanup := func() {
+		p.removeIf(replyKey, func(h *event) bool { return h == e })
+		finish(nil)
+	}
+	timer = p.clock.AfterFunc(p.replyTimeout, cleanup)
+	ctx := context.WithValue(context.Background(), replyToKey{}, replyTo{bus: p, key: replyKey})
+	if err := p.SendCtx(ctx, eventKey, args...); err != nil {
+		cleanup()
+	}
+	return replyKey, ch
+}
+
+// Reply 在 RequestAsync 的订阅中应答请求，ctx 为订阅注入的 context，不是请求时返回 ErrNotFound
+func Reply(ctx context.Context, values ...interface{}) error {
+	to, ok := ctx.Value(replyToKey{}).(replyTo)
+	if !ok {
+		return ErrNotFound
+	}
+	return to.bus.send(&sender{key: to.key, args: values})
+}
@@ -0,0 +1,52 @@
+package eventbus
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventBus_RequestAsync(t *testing.T) {
+	events := New()
+	defer events.Close()
+	assert.NoError(t, events.On("sum", func(ctx context.Context, a, b int) {
+		assert.NoError(t, Reply(ctx, a+b))
+	}))
+
+	replyKey, reply := events.RequestAsync("sum", 1, 2)
+	assert.Equal(t, "reply.1", replyKey)
+	select {
+	case values := <-reply:
+		assert.Equal(t, []interface{}{3}, values)
+	case <-time.After(time.Second):
+		t.Fatal("reply not received")
+	}
+	_, ok := <-reply
+	assert.False(t, ok)
+	// 应答后订阅被移除
+	assert.Len(t, events.handlers(replyKey), 0)
+
+	assert.EqualError(t, Reply(context.Background(), 1), ErrNotFound.Error())
+}
+
+func TestEventBus_RequestAsyncTimeout(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	events := New(WithClock(clock), WithReplyTimeout(time.Second))
+	defer events.Close()
+	assert.NoError(t, events.On("silent", func() {}))
+
+	replyKey, reply := events.RequestAsync("silent")
+	assert.Len(t, events.handlers(replyKey), 1)
+	clock.Advance(time.Second)
+	_, ok := <-reply
+	assert.False(t, ok)
+	assert.Len(t, events.handlers(replyKey), 0)
+
+	// 请求发送失败时立即关闭
+	replyKey, reply = events.RequestAsync("missing")
+	_, ok = <-reply
+	assert.False(t, ok)
+	assert.Len(t, events.handlers(replyKey), 0)
+}
@@ -0,0 +1,99 @@
+package eventbus
+
+import (
+	"sync"
+	"time"
+)
+
+// defaultResultTTL SendTracked 的结果默认保留时长
+const defaultResultTTL = time.Minute
+
+// WithResultTTL 设置 SendTracked 的结果在调用结束后保留的时长，过期后 Result 返回 ErrNotFound
+func WithResultTTL(ttl time.Duration) Option {
+	return func(p *EventBus) {
+		p.sendResults.ttl = ttl
+	}
+}
+
+// sendResult 一次 SendTracked 的结果
+type sendResult struct {
+	done bool
+	err  error
+}
+
+// sendResults 按发送ID保存 SendTracked 的结果，结束的结果按结束顺序过期
+type sendResults struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	results map[uint64]*sendResult
+	// expiring 已经结束的发送，按结束时间排列
+	expiring []expiringResult
+}
+
+type expiringResult struct {
+	id uint64
+	at time.Time
+}
+
+// expire 移除 now 之前过期的结果，调用方需持有 mu
+func (r *sendResults) expire(now time.Time) {
+	n := 0
+	for n < len(r.expiring) && !r.expiring[n].at.After(now) {
+		delete(r.results, r.expiring[n].id)
+		n++
+	}
+	r.expiring = r.expiring[n:]
+}
+
+// SendTracked 同 Send，返回发送ID，之后可以通过 Result 查询异步调用的结果，无需持有 channel
+// 被去重合并、没有需要调用的订阅或者被拒绝时返回的ID为 0。WithAutoSyncForErrorReturns 下同步调用的订阅返回错误时
+// 同时返回发送ID，全部订阅都是同步调用时返回时结果已经结束，否则 Result 记录异步调用部分的结果
+func (p *EventBus) SendTracked(eventKey string, args ...interface{}) (uint64, error) {
+	s := &sender{key: eventKey, args: args, tracked: true}
+	err := p.send(s)
+	return s.id, err
+}
+
+// Result 返回 SendTracked 的发送是否调用结束以及调用返回的错误
+// 发送ID不存在或结果已经超过 WithResultTTL 设置的时长时返回 ErrNotFound
+func (p *EventBus) Result(sendID uint64) (done bool, err error) {
+	r := p.sendResults
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.expire(p.clock.Now())
+	result, ok := r.results[sendID]
+	if !ok {
+		return false, ErrNotFound
+	}
+	return result.done, result.err
+}
+
+// startResult 入队前登记 SendTracked 的发送
+func (p *EventBus) startResult(s *sender) {
+	if !s.tracked {
+		return
+	}
+	r := p.sendResults
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.expire(p.clock.Now())
+	r.results[s.id] = &sendResult{}
+}
+
+// finishResult 记录 SendTracked 的发送结果，开始计算过期时间
+func (p *EventBus) finishResult(s *sender, err error) {
+	if !s.tracked {
+		return
+	}
+	r := p.sendResults
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	result, ok := r.results[s.id]
+	if !ok || result.done {
+		return
+	}
+	result.done, result.err = true, err
+	now := p.clock.Now()
+	r.expiring = append(r.expiring, expiringResult{id: s.id, at: now.Add(r.ttl)})
+	r.expire(now)
+}
@@ -0,0 +1,56 @@
+package eventbus
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventBus_SendTracked(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	events := New(WithClock(clock), WithResultTTL(time.Minute))
+	defer events.Close()
+	failed := errors.New("failed")
+	release := make(chan struct{})
+	assert.NoError(t, events.On("charge", func(amount int) error {
+		<-release
+		if amount < 0 {
+			return failed
+		}
+		return nil
+	}))
+
+	bad, err := events.SendTracked("charge", -1)
+	assert.NoError(t, err)
+	ok, err := events.SendTracked("charge", 1)
+	assert.NoError(t, err)
+	assert.NotEqual(t, bad, ok)
+	done, err := events.Result(bad)
+	assert.False(t, done)
+	assert.NoError(t, err)
+
+	close(release)
+	assert.Eventually(t, func() bool {
+		done, _ := events.Result(bad)
+		return done
+	}, time.Second, time.Millisecond)
+	done, err = events.Result(bad)
+	assert.True(t, done)
+	assert.Equal(t, failed, err)
+	assert.Eventually(t, func() bool {
+		done, err := events.Result(ok)
+		return done && err == nil
+	}, time.Second, time.Millisecond)
+
+	// 超过保留时长后结果被移除
+	clock.Advance(time.Minute)
+	_, err = events.Result(bad)
+	assert.Equal(t, ErrNotFound, err)
+	_, err = events.Result(0)
+	assert.Equal(t, ErrNotFound, err)
+
+	_, err = events.SendTracked("missing")
+	assert.Equal(t, ErrNotFound, err)
+}
@@ -0,0 +1,46 @@
+package eventbus
+
+// SendRetained 调用事件并保留本次入参，之后可以通过 Retained 读取
+// 事件尚未注册时入参依旧会被保留，此时返回 ErrNotFound
+func (p *EventBus) SendRetained(eventKey string, args ...interface{}) error {
+	p.stickyMu.Lock()
+	defer p.stickyMu.Unlock()
+	err := p.send(&sender{key: eventKey, args: args})
+	if err == nil || err == ErrNotFound {
+		p.retain(eventKey, args)
+	}
+	return err
+}
+
+// OnSticky 注册订阅，事件已有保留的入参时立即用它调用一次新订阅
+// 注册和读取保留值与 SendRetained 互斥，新订阅恰好收到一次最新的保留值，之后的 SendRetained 正常调用
+// 立即调用与普通发送一样异步执行，入参不匹配时通过 OnSendError 报告，订阅依旧保持注册
+func (p *EventBus) OnSticky(eventKey string, call interface{}) error {
+	p.stickyMu.Lock()
+	defer p.stickyMu.Unlock()
+	e := &event{key: eventKey, call: call}
+	if err := p.on(e, false); err != nil {
+		return err
+	}
+	if args, ok := p.Retained(eventKey); ok {
+		p.SendTo(Subscription{Key: eventKey, ID: e.id}, args...)
+	}
+	return nil
+}
+
+// Retained 返回事件最近一次保留的入参
+func (p *EventBus) Retained(eventKey string) ([]interface{}, bool) {
+	p.rmu.RLock()
+	defer p.rmu.RUnlock()
+	args, ok := p.retained[eventKey]
+	return args, ok
+}
+
+func (p *EventBus) retain(eventKey string, args []interface{}) {
+	p.rmu.Lock()
+	defer p.rmu.Unlock()
+	if p.retained == nil {
+		p.retained = make(map[string][]interface{})
+	}
+	p.retained[eventKey] = args
+}
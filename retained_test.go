@@ -0,0 +1,85 @@
+package eventbus
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventBus_SendRetained(t *testing.T) {
+	events := New()
+	defer events.Close()
+
+	// 未注册时依旧保留
+	err := events.SendRetained("config", "debug")
+	assert.EqualError(t, err, ErrNotFound.Error())
+	args, ok := events.Retained("config")
+	assert.True(t, ok)
+	assert.Equal(t, []interface{}{"debug"}, args)
+
+	got := make(chan string, 1)
+	events.On("config", func(level string) {
+		got <- level
+	})
+	err = events.SendRetained("config", "info")
+	assert.NoError(t, err)
+	select {
+	case level := <-got:
+		assert.Equal(t, "info", level)
+	case <-time.After(time.Second):
+		t.Fatal("event not received")
+	}
+	args, _ = events.Retained("config")
+	assert.Equal(t, []interface{}{"info"}, args)
+
+	// 参数不匹配时不保留
+	err = events.SendRetained("config", "warn", 1)
+	assert.EqualError(t, err, ErrArgsNotMatch.Error())
+	args, _ = events.Retained("config")
+	assert.Equal(t, []interface{}{"info"}, args)
+
+	_, ok = events.Retained("missing")
+	assert.False(t, ok)
+}
+
+func TestEventBus_OnSticky(t *testing.T) {
+	events := New()
+	events.SendRetained("config", "debug")
+	events.SendRetained("config", "info")
+
+	var mu sync.Mutex
+	var got []string
+	assert.NoError(t, events.OnSticky("config", func(level string) {
+		mu.Lock()
+		got = append(got, level)
+		mu.Unlock()
+	}))
+	events.Close()
+	// 只收到最新的保留值，没有重复
+	assert.Equal(t, []string{"info"}, got)
+
+	// 与 SendRetained 并发注册时，每个值最多收到一次，并且不会错过最后一次发送
+	events = New()
+	const n = 200
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 1; i <= n; i++ {
+			events.SendRetained("seq", i)
+		}
+	}()
+	seen := map[int]int{}
+	assert.NoError(t, events.OnSticky("seq", func(i int) {
+		mu.Lock()
+		seen[i]++
+		mu.Unlock()
+	}))
+	<-done
+	events.Close()
+	for i, times := range seen {
+		assert.Equal(t, 1, times, "value %d", i)
+	}
+	assert.Equal(t, 1, seen[n])
+}
@@ -0,0 +1,119 @@
+package eventbus
+
+import (
+	"sync"
+	"time"
+)
+
+// a schedule 一个尚未结束的定时或周期发送
+type schedule struct {
+	mu      sync.Mutex
+	timer   Timer
+	stopped bool
+}
+
+// stop 停止发送，返回是否是本次调用停止的
+func (s *schedule) stop() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopped {
+		return false
+	}
+	s.stopped = true
+	if s.timer != nil {
+		s.timer.Stop()
+	}
+	return true
+}
+
+// SendEvery 每隔 interval 调用一次事件，直到调用返回的 cancel、CancelScheduled 或订阅器关闭
+// 每次到期时校验入参，失败时通过 OnSendError 报告，不会停止之后的发送。interval 不大于 0 时返回 ErrInvalidInterval
+func (p *EventBus) SendEvery(interval time.Duration, eventKey string, args ...interface{}) (cancel func(), err error) {
+	if interval <= 0 {
+		return nil, ErrInvalidInterval
+	}
+	s, err := p.schedule(eventKey, interval, true, args)
+	if err != nil {
+		return nil, err
+	}
+	return func() { p.unschedule(eventKey, s) }, nil
+}
+
+// WithMaxSchedules 限制尚未结束的 SendAfter 和 SendEvery 总数，达到 n 之后返回 ErrScheduleLimit
+// 用于防止调用方的错误导致定时器无限增长，到期或取消后释放名额
+func WithMaxSchedules(n int) Option {
+	return func(p *EventBus) {
+		p.maxSchedules = n
+	}
+}
+
+// CancelScheduled 取消 eventKey 全部尚未执行的 SendAfter 和 SendEvery
+func (p *EventBus) CancelScheduled(eventKey string) {
+	p.smu.Lock()
+	pending := p.schedules[eventKey]
+	delete(p.schedules, eventKey)
+	p.scheduled -= len(pending)
+	p.smu.Unlock()
+	for s := range pending {
+		s.stop()
+	}
+}
+
+// schedule 登记一次定时发送，repeat 为 true 时每隔 d 重复发送
+func (p *EventBus) schedule(eventKey string, d time.Duration, repeat bool, args []interface{}) (*schedule, error) {
+	if p.isClosed() {
+		return nil, ErrBusClosed
+	}
+	s := &schedule{}
+	p.smu.Lock()
+	if p.maxSchedules > 0 && p.scheduled >= p.maxSchedules {
+		p.smu.Unlock()
+		return nil, ErrScheduleLimit
+	}
+	p.scheduled++
+	if p.schedules == nil {
+		p.schedules = make(map[string]map[*schedule]struct{})
+	}
+	if p.schedules[eventKey] == nil {
+		p.schedules[eventKey] = make(map[*schedule]struct{})
+	}
+	p.schedules[eventKey][s] = struct{}{}
+	p.smu.Unlock()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.timer = p.clock.AfterFunc(d, func() {
+		s.mu.Lock()
+		if s.stopped {
+			s.mu.Unlock()
+			return
+		}
+		s.mu.Unlock()
+		if p.send(&sender{key: eventKey, args: args}) == ErrBusClosed || !repeat {
+			p.unschedule(eventKey, s)
+			return
+		}
+		s.mu.Lock()
+		if !s.stopped {
+			s.timer.Reset(d)
+		}
+		s.mu.Unlock()
+	})
+	return s, nil
+}
+
+// unschedule 停止并移除定时发送 s
+func (p *EventBus) unschedule(eventKey string, s *schedule) {
+	s.stop()
+	p.smu.Lock()
+	defer p.smu.Unlock()
+	if pending, ok := p.schedules[eventKey]; ok {
+		if _, ok := pending[s]; ok {
+			delete(pending, s)
+			p.scheduled--
+		}
+		if len(pending) == 0 {
+			delete(p.schedules, eventKey)
+		}
+	}
+}
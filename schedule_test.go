@@ -0,0 +1,70 @@
+package eventbus
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventBus_SendEvery(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	events := New(WithClock(clock))
+	var ticks int32
+	assert.NoError(t, events.On("tick", func() { atomic.AddInt32(&ticks, 1) }))
+	_, err := events.SendEvery(0, "tick")
+	assert.Equal(t, ErrInvalidInterval, err)
+	cancel, err := events.SendEvery(time.Second, "tick")
+	assert.NoError(t, err)
+	clock.Advance(3 * time.Second)
+	cancel()
+	clock.Advance(3 * time.Second)
+	events.Close()
+	assert.Equal(t, int32(3), atomic.LoadInt32(&ticks))
+}
+
+func TestEventBus_CancelScheduled(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	events := New(WithClock(clock))
+	var report, other int32
+	assert.NoError(t, events.On("report", func() { atomic.AddInt32(&report, 1) }))
+	assert.NoError(t, events.On("other", func() { atomic.AddInt32(&other, 1) }))
+	assert.NoError(t, events.SendAfter(time.Second, "report"))
+	assert.NoError(t, events.SendAfter(2*time.Second, "report"))
+	_, err := events.SendEvery(time.Second, "report")
+	assert.NoError(t, err)
+	assert.NoError(t, events.SendAfter(time.Second, "other"))
+
+	// 一次取消事件全部的定时发送，其他事件不受影响
+	events.CancelScheduled("report")
+	clock.Advance(5 * time.Second)
+	events.Close()
+	assert.Equal(t, int32(0), atomic.LoadInt32(&report))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&other))
+	assert.Empty(t, events.schedules)
+}
+
+func TestEventBus_WithMaxSchedules(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	events := New(WithClock(clock), WithMaxSchedules(2))
+	defer events.Close()
+	var ticks int32
+	assert.NoError(t, events.On("tick", func() { atomic.AddInt32(&ticks, 1) }))
+	assert.NoError(t, events.SendAfter(time.Second, "tick"))
+	cancel, err := events.SendEvery(time.Second, "tick")
+	assert.NoError(t, err)
+	assert.Equal(t, ErrScheduleLimit, events.SendAfter(time.Second, "tick"))
+	_, err = events.SendEvery(time.Second, "tick")
+	assert.Equal(t, ErrScheduleLimit, err)
+
+	// 取消或到期后释放名额
+	cancel()
+	_, err = events.SendEvery(time.Second, "tick")
+	assert.NoError(t, err)
+	assert.Equal(t, ErrScheduleLimit, events.SendAfter(time.Second, "tick"))
+	events.CancelScheduled("tick")
+	assert.NoError(t, events.SendAfter(time.Second, "tick"))
+	assert.NoError(t, events.SendAfter(time.Second, "tick"))
+	assert.Equal(t, ErrScheduleLimit, events.SendAfter(time.Second, "tick"))
+}
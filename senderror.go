@@ -0,0 +1,22 @@
+package eventbus
+
+// OnSendError 注册发送失败的订阅，任何发送返回错误(ErrNotFound、ErrArgsNotMatch 等)时在发送方协程中调用
+// 用于集中监控发送方的错误用法，订阅执行时的错误不会触发
+func (p *EventBus) OnSendError(call func(eventKey string, err error)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.sendErrors = append(p.sendErrors[:len(p.sendErrors):len(p.sendErrors)], call)
+}
+
+// sendError 发送失败时依次调用 OnSendError 注册的订阅
+func (p *EventBus) sendError(eventKey string, err error) {
+	if err == nil {
+		return
+	}
+	p.mu.RLock()
+	calls := p.sendErrors
+	p.mu.RUnlock()
+	for _, call := range calls {
+		call(eventKey, err)
+	}
+}
@@ -0,0 +1,58 @@
+package eventbus
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventBus_OnSendError(t *testing.T) {
+	events := New()
+	defer events.Close()
+	type failure struct {
+		key string
+		err error
+	}
+	var failures []failure
+	events.OnSendError(func(eventKey string, err error) {
+		failures = append(failures, failure{eventKey, err})
+	})
+	assert.NoError(t, events.On("add", func(a, b int) {}))
+
+	assert.NoError(t, events.Send("add", 1, 2))
+	assert.Error(t, events.Send("add", 1))
+	assert.Error(t, events.Send("missing"))
+	assert.Error(t, events.SendTo(Subscription{Key: "add"}, 1, 2))
+	assert.Error(t, events.SendBubble("add.more", 1))
+
+	assert.Equal(t, []failure{
+		{"add", ErrArgsNotMatch},
+		{"missing", ErrNotFound},
+		{"add", ErrNotFound},
+		{"add.more", ErrArgsNotMatch},
+	}, failures)
+}
+
+func TestEventBus_OnSendErrorWaitSub(t *testing.T) {
+	events := New()
+	defer events.Close()
+	var mu sync.Mutex
+	var failures []error
+	events.OnSendError(func(eventKey string, err error) {
+		mu.Lock()
+		failures = append(failures, err)
+		mu.Unlock()
+	})
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		events.On("late", func() {})
+	}()
+	// 等待期间的重试不报告
+	assert.NoError(t, events.SendWaitSub("late", time.Second))
+	assert.Equal(t, ErrNotFound, events.SendWaitSub("never", 10*time.Millisecond))
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []error{ErrNotFound}, failures)
+}
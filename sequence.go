@@ -0,0 +1,21 @@
+package eventbus
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// Sequence 返回 ctx 所属发送的序号，序号即发送ID，按 Send 成功的顺序单调递增
+// 订阅是异步调用的，可以比较收到的序号判断是否乱序
+func Sequence(ctx context.Context) (uint64, bool) {
+	chain := CausationChain(ctx)
+	if len(chain) == 0 {
+		return 0, false
+	}
+	return chain[len(chain)-1].ID, true
+}
+
+// LastSequence 返回最近一次发送的序号，尚未发送时返回 0
+func (p *EventBus) LastSequence() uint64 {
+	return atomic.LoadUint64(&p.sendSeq)
+}
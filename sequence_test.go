@@ -0,0 +1,40 @@
+package eventbus
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSequence(t *testing.T) {
+	events := New()
+	var (
+		mu   sync.Mutex
+		seqs []uint64
+	)
+	assert.NoError(t, events.On("tick", func(ctx context.Context, i int) {
+		seq, ok := Sequence(ctx)
+		assert.True(t, ok)
+		mu.Lock()
+		seqs = append(seqs, seq)
+		mu.Unlock()
+	}))
+	assert.Equal(t, uint64(0), events.LastSequence())
+	last := uint64(0)
+	for i := 0; i < 5; i++ {
+		assert.NoError(t, events.Send("tick", i))
+		seq := events.LastSequence()
+		assert.True(t, seq > last)
+		last = seq
+	}
+	events.Close()
+
+	sort.Slice(seqs, func(i, j int) bool { return seqs[i] < seqs[j] })
+	assert.Equal(t, []uint64{1, 2, 3, 4, 5}, seqs)
+
+	_, ok := Sequence(context.Background())
+	assert.False(t, ok)
+}
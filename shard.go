@@ -0,0 +1,44 @@
+package eventbus
+
+import (
+	"fmt"
+	"hash/fnv"
+)
+
+// OnShard 注册分片订阅，发送时按入参计算分片，只调用 shard 对应的订阅，同一个实体的事件总是由同一个分片处理
+// 分片由 hash(入参) % totalShards 决定，入参位置默认是第一个，可以通过 WithShardArg 修改
+// 入参不足时不调用任何分片订阅。shard 不在 [0, totalShards) 内时返回 ErrInvalidShard
+func (p *EventBus) OnShard(eventKey string, shard, totalShards int, call interface{}) error {
+	if totalShards <= 0 || shard < 0 || shard >= totalShards {
+		return ErrInvalidShard
+	}
+	return p.on(&event{key: eventKey, call: call, shard: shard, shards: totalShards}, false)
+}
+
+// WithShardArg OnShard 使用第 i 个入参计算分片，i 小于 0 时忽略，依旧使用第一个入参
+func WithShardArg(i int) Option {
+	return func(p *EventBus) {
+		if i >= 0 {
+			p.shardArg = i
+		}
+	}
+}
+
+// owns 判断本次发送是否属于 e 负责的分片
+func (e *event) owns(args []interface{}) bool {
+	i := 0
+	if e.bus != nil {
+		i = e.bus.shardArg
+	}
+	if i >= len(args) {
+		return false
+	}
+	return shardOf(args[i], e.shards) == e.shard
+}
+
+// shardOf 按入参的文本形式计算分片，相同的值总是得到相同的分片
+func shardOf(v interface{}, shards int) int {
+	h := fnv.New32a()
+	fmt.Fprint(h, v)
+	return int(h.Sum32() % uint32(shards))
+}
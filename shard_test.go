@@ -0,0 +1,72 @@
+package eventbus
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventBus_OnShard(t *testing.T) {
+	events := New()
+	const shards = 3
+	var mu sync.Mutex
+	owner := map[string]map[int]int{}
+	for i := 0; i < shards; i++ {
+		shard := i
+		assert.NoError(t, events.OnShard("order", shard, shards, func(id string) {
+			mu.Lock()
+			defer mu.Unlock()
+			if owner[id] == nil {
+				owner[id] = map[int]int{}
+			}
+			owner[id][shard]++
+		}))
+	}
+	for round := 0; round < 3; round++ {
+		for i := 0; i < 20; i++ {
+			assert.NoError(t, events.SendSync("order", fmt.Sprintf("user-%d", i)))
+		}
+	}
+	events.Close()
+
+	used := map[int]bool{}
+	for id, got := range owner {
+		// 同一个实体的事件总是由同一个分片处理
+		if assert.Len(t, got, 1, id) {
+			for shard, n := range got {
+				assert.Equal(t, 3, n)
+				assert.Equal(t, shardOf(id, shards), shard)
+				used[shard] = true
+			}
+		}
+	}
+	assert.Len(t, owner, 20)
+	assert.Len(t, used, shards)
+}
+
+func TestEventBus_WithShardArg(t *testing.T) {
+	events := New(WithShardArg(1))
+	defer events.Close()
+	got := make([]int, 2)
+	for i := range got {
+		shard := i
+		assert.NoError(t, events.OnShard("move", shard, 2, func(step int, id string) { got[shard]++ }))
+	}
+	want := shardOf("robot", 2)
+	for step := 0; step < 4; step++ {
+		assert.NoError(t, events.SendSync("move", step, "robot"))
+	}
+	assert.Equal(t, 4, got[want])
+	assert.Equal(t, 0, got[1-want])
+
+	assert.Equal(t, ErrInvalidShard, events.OnShard("move", 2, 2, func() {}))
+	assert.Equal(t, ErrInvalidShard, events.OnShard("move", 0, 0, func() {}))
+	assert.Equal(t, ErrInvalidShard, events.OnShard("move", -1, 2, func() {}))
+
+	// 负数位置被忽略，使用第一个入参
+	ignored := New(WithShardArg(-1))
+	defer ignored.Close()
+	assert.Equal(t, 0, ignored.shardArg)
+}
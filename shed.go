@@ -0,0 +1,22 @@
+package eventbus
+
+// WithLoadShedding 开启过载保护，队列中等待的发送超过 threshold 时，新的低优先级(优先级小于等于 0)异步发送
+// 不再入队，交给 shedFn 处理后返回 nil；高优先级的发送和同步发送不受影响
+func WithLoadShedding(threshold int, shedFn func(key string, args []interface{})) Option {
+	return func(p *EventBus) {
+		p.shedThreshold = threshold
+		p.shedFn = shedFn
+	}
+}
+
+// shed 队列积压超过阈值时丢弃低优先级的异步发送，返回 true
+func (p *EventBus) shed(s *sender) bool {
+	if p.shedFn == nil || s.sync != nil || s.notify || s.ack != nil || s.priority > 0 {
+		return false
+	}
+	if p.queue.len() <= p.shedThreshold {
+		return false
+	}
+	p.shedFn(s.key, s.args)
+	return true
+}
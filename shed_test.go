@@ -0,0 +1,41 @@
+package eventbus
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventBus_WithLoadShedding(t *testing.T) {
+	var (
+		mu   sync.Mutex
+		shed []interface{}
+	)
+	events := New(WithLoadShedding(2, func(key string, args []interface{}) {
+		mu.Lock()
+		shed = append(shed, args[0])
+		mu.Unlock()
+	}))
+	got := make(chan int, 10)
+	assert.NoError(t, events.On("job", func(n int) { got <- n }))
+	// 暂停后发送积压在队列中
+	events.Pause()
+	for i := 0; i < 5; i++ {
+		assert.NoError(t, events.Send("job", i))
+	}
+	assert.NoError(t, events.SendPriority("job", 1, 100))
+	assert.Len(t, events.PendingSnapshot(), 4)
+	mu.Lock()
+	assert.Equal(t, []interface{}{3, 4}, shed)
+	mu.Unlock()
+
+	events.Resume()
+	events.Close()
+	close(got)
+	var received []int
+	for n := range got {
+		received = append(received, n)
+	}
+	assert.ElementsMatch(t, []int{0, 1, 2, 100}, received)
+}
@@ -0,0 +1,21 @@
+package eventbus
+
+// WithAtomicClose 关闭与发送互斥，与 Close 并发的 Send 要么完整执行并在关闭前入队，要么直接返回 ErrBusClosed
+// 返回 ErrBusClosed 的发送没有任何副作用：不调用同步订阅、不记录历史也不占用去重窗口
+// 关闭时等待已经准入的发送全部入队后才关闭队列，这些发送中的订阅继续发送时返回 ErrBusClosed
+func WithAtomicClose() Option {
+	return func(p *EventBus) {
+		p.atomicClose = true
+	}
+}
+
+// admit 在关闭之前登记一次发送，已经关闭时返回 false，登记成功的发送结束后需要调用 admitting.Done
+func (p *EventBus) admit() bool {
+	p.closeMu.RLock()
+	defer p.closeMu.RUnlock()
+	if p.isClosed() {
+		return false
+	}
+	p.admitting.Add(1)
+	return true
+}
@@ -0,0 +1,45 @@
+package eventbus
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithAtomicClose(t *testing.T) {
+	for i := 0; i < 10; i++ {
+		events := New(WithAtomicClose(), WithAutoSyncForErrorReturns(), WithHistory(1000))
+		var syncs, asyncs int32
+		assert.NoError(t, events.On("job", func() error { atomic.AddInt32(&syncs, 1); return nil }))
+		assert.NoError(t, events.On("job", func() { atomic.AddInt32(&asyncs, 1) }))
+		var (
+			wg   sync.WaitGroup
+			sent int32
+		)
+		for j := 0; j < 4; j++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for k := 0; k < 200; k++ {
+					err := events.Send("job")
+					if err == ErrBusClosed {
+						return
+					}
+					assert.NoError(t, err)
+					atomic.AddInt32(&sent, 1)
+				}
+			}()
+		}
+		time.Sleep(100 * time.Microsecond)
+		events.Close()
+		wg.Wait()
+		// 成功的发送完整调用全部订阅，返回 ErrBusClosed 的发送没有调用任何订阅，也没有记录历史
+		assert.Equal(t, sent, atomic.LoadInt32(&syncs))
+		assert.Equal(t, sent, atomic.LoadInt32(&asyncs))
+		assert.Len(t, events.history.last("job", 1000), int(sent))
+		assert.Equal(t, ErrBusClosed, events.Send("job"))
+	}
+}
@@ -0,0 +1,59 @@
+package eventbus
+
+import (
+	"fmt"
+	"sync"
+)
+
+// SingleBus 以类型化的 K 作为事件的订阅器包装，每个事件只允许一个订阅
+// K 通常是自定义的枚举类型，事件名为 fmt.Sprint(key)，实现 String 方法的枚举使用 String 的结果
+type SingleBus[K comparable] struct {
+	bus     *EventBus
+	mu      sync.Mutex
+	handles map[K]Subscription
+}
+
+// NewSingleBus 在 bus 之上创建 SingleBus，bus 的生命周期仍由调用方管理
+func NewSingleBus[K comparable](bus *EventBus) *SingleBus[K] {
+	return &SingleBus[K]{bus: bus, handles: make(map[K]Subscription)}
+}
+
+// Bus 返回底层的订阅器
+func (b *SingleBus[K]) Bus() *EventBus {
+	return b.bus
+}
+
+// On 注册 key 的订阅，key 已有订阅时返回包装了 ErrExists 的错误，错误信息包含 key
+func (b *SingleBus[K]) On(key K, call interface{}) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if handle, ok := b.handles[key]; ok && b.bus.lookup(handle) != nil {
+		return fmt.Errorf("%w: %v already has a handler", ErrExists, key)
+	}
+	handle, err := b.bus.Subscribe(fmt.Sprint(key), call)
+	if err != nil {
+		return err
+	}
+	b.handles[key] = handle
+	return nil
+}
+
+// Off 移除 key 的订阅，之后可以重新注册
+func (b *SingleBus[K]) Off(key K) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if handle, ok := b.handles[key]; ok {
+		b.bus.Unsubscribe(handle)
+		delete(b.handles, key)
+	}
+}
+
+// Send 同 EventBus.Send
+func (b *SingleBus[K]) Send(key K, args ...interface{}) error {
+	return b.bus.Send(fmt.Sprint(key), args...)
+}
+
+// SendSync 同 EventBus.SendSync
+func (b *SingleBus[K]) SendSync(key K, args ...interface{}) error {
+	return b.bus.SendSync(fmt.Sprint(key), args...)
+}
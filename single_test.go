@@ -0,0 +1,44 @@
+package eventbus
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type singleKey int
+
+const (
+	singleCreated singleKey = iota
+	singlePaid
+)
+
+func (e singleKey) String() string {
+	return [...]string{"order.created", "order.paid"}[e]
+}
+
+func TestSingleBus(t *testing.T) {
+	events := New()
+	defer events.Close()
+	bus := NewSingleBus[singleKey](events)
+	var got []int
+	assert.NoError(t, bus.On(singleCreated, func(id int) { got = append(got, id) }))
+	assert.NoError(t, bus.On(singlePaid, func(id int) { got = append(got, -id) }))
+
+	err := bus.On(singleCreated, func(id int) {})
+	assert.True(t, errors.Is(err, ErrExists))
+	assert.EqualError(t, err, "event already exists: order.created already has a handler")
+
+	assert.NoError(t, bus.SendSync(singleCreated, 1))
+	assert.NoError(t, bus.SendSync(singlePaid, 2))
+	assert.Equal(t, []int{1, -2}, got)
+	assert.Equal(t, []string{"order.created", "order.paid"}, bus.Bus().Keys())
+
+	// 移除后可以重新注册
+	bus.Off(singleCreated)
+	assert.Equal(t, ErrNotFound, bus.SendSync(singleCreated, 3))
+	assert.NoError(t, bus.On(singleCreated, func(id int) { got = append(got, id*10) }))
+	assert.NoError(t, bus.SendSync(singleCreated, 3))
+	assert.Equal(t, []int{1, -2, 30}, got)
+}
@@ -0,0 +1,56 @@
+package eventbus
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// SendStarted 调用事件，每个订阅在独立的协程中并发执行，全部订阅开始执行后返回，不等待执行结束
+// 介于 Send 和 SendSync 之间：返回时保证调用已经开始。订阅之间并发执行，StopPropagation 不生效
+func (p *EventBus) SendStarted(eventKey string, args ...interface{}) (err error) {
+	if target := p.federation.target(eventKey); target != nil && target != p {
+		return target.SendStarted(eventKey, args...)
+	}
+	defer func() { p.sendError(eventKey, err) }()
+	if p.isClosed() {
+		return ErrBusClosed
+	}
+	s := &sender{key: eventKey, args: args}
+	if dup, err := p.prepare(s); dup || err != nil {
+		return err
+	}
+	s.id = atomic.AddUint64(&p.sendSeq, 1)
+	atomic.AddInt64(&p.stats.sent, 1)
+	p.running.Add(1)
+	var started, done sync.WaitGroup
+	errs := make([]error, len(s.events))
+	for i, e := range s.events {
+		started.Add(1)
+		done.Add(1)
+		one := *s
+		one.events, one.levels = []*event{e}, nil
+		go func(i int) {
+			defer done.Done()
+			started.Done()
+			errs[i] = one.Call()
+		}(i)
+	}
+	go func() {
+		defer p.running.Done()
+		defer p.release()
+		done.Wait()
+		var first error
+		for _, err := range errs {
+			if err != nil {
+				first = err
+				break
+			}
+		}
+		if first != nil {
+			atomic.AddInt64(&p.stats.failed, 1)
+		}
+		p.recordOutcome(eventKey, first)
+	}()
+	started.Wait()
+	return nil
+}
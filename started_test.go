@@ -0,0 +1,27 @@
+package eventbus
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventBus_SendStarted(t *testing.T) {
+	events := New()
+	var begun int32
+	release := make(chan struct{})
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, events.On("job", func() {
+			atomic.AddInt32(&begun, 1)
+			<-release
+		}))
+	}
+	assert.NoError(t, events.SendStarted("job"))
+	// 返回时不等待订阅结束，三个订阅同时处于执行中
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&begun) == 3 }, time.Second, time.Millisecond)
+	close(release)
+	events.Close()
+	assert.Equal(t, ErrBusClosed, events.SendStarted("job"))
+}
@@ -0,0 +1,65 @@
+package eventbus
+
+import (
+	"encoding/json"
+	"sync/atomic"
+)
+
+// busState 订阅器中可以序列化的状态
+// 订阅的调用方法无法序列化，不包含在内，需要在 Import 之后重新注册
+type busState struct {
+	Retained map[string][]interface{}   `json:"retained,omitempty"`
+	History  map[string][][]interface{} `json:"history,omitempty"`
+	Counters busCounters                `json:"counters"`
+	Config   busConfig                  `json:"config"`
+}
+
+type busCounters struct {
+	Sent   int64 `json:"sent"`
+	Failed int64 `json:"failed"`
+}
+
+type busConfig struct {
+	PriorityAging float64 `json:"priority_aging"`
+}
+
+// Export 导出订阅器的状态(保留的入参、WithHistory 记录的历史、发送计数、配置)，用于测试时构造已知状态
+// 不包含订阅的调用方法，也不包含队列中尚未调用的发送
+func (p *EventBus) Export() ([]byte, error) {
+	p.queue.mu.Lock()
+	aging := p.queue.aging
+	p.queue.mu.Unlock()
+	state := busState{
+		History: p.history.snapshot(),
+		Counters: busCounters{
+			Sent:   atomic.LoadInt64(&p.stats.sent),
+			Failed: atomic.LoadInt64(&p.stats.failed),
+		},
+		Config: busConfig{PriorityAging: aging},
+	}
+	p.rmu.RLock()
+	state.Retained = p.retained
+	data, err := json.Marshal(state)
+	p.rmu.RUnlock()
+	return data, err
+}
+
+// Import 导入 Export 导出的状态，覆盖当前保留的入参、历史、发送计数和配置
+// 没有开启 WithHistory 时忽略导入的历史，开启时每个事件最多保留 WithHistory 的条数
+// 入参经过 JSON 编码，数字会被还原为 float64，结构体会被还原为 map[string]interface{}
+func (p *EventBus) Import(data []byte) error {
+	var state busState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return err
+	}
+	p.queue.mu.Lock()
+	p.queue.aging = state.Config.PriorityAging
+	p.queue.mu.Unlock()
+	atomic.StoreInt64(&p.stats.sent, state.Counters.Sent)
+	atomic.StoreInt64(&p.stats.failed, state.Counters.Failed)
+	p.history.restore(state.History)
+	p.rmu.Lock()
+	p.retained = state.Retained
+	p.rmu.Unlock()
+	return nil
+}
@@ -0,0 +1,54 @@
+package eventbus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventBus_ExportImport(t *testing.T) {
+	events := New(WithPriorityAging(0.5))
+	defer events.Close()
+	events.SendRetained("config", "debug", 1)
+
+	data, err := events.Export()
+	assert.NoError(t, err)
+
+	fresh := New()
+	defer fresh.Close()
+	err = fresh.Import(data)
+	assert.NoError(t, err)
+	args, ok := fresh.Retained("config")
+	assert.True(t, ok)
+	// 经过 JSON 编码后数字还原为 float64
+	assert.Equal(t, []interface{}{"debug", float64(1)}, args)
+	assert.Equal(t, 0.5, fresh.queue.aging)
+
+	err = fresh.Import([]byte("{"))
+	assert.Error(t, err)
+}
+
+func TestEventBus_ExportImportHistory(t *testing.T) {
+	events := New(WithHistory(2))
+	defer events.Close()
+	assert.NoError(t, events.On("add", func(int) {}))
+	for i := 1; i <= 3; i++ {
+		assert.NoError(t, events.Send("add", i))
+	}
+	assert.Error(t, events.Send("add"))
+	data, err := events.Export()
+	assert.NoError(t, err)
+
+	fresh := New(WithHistory(1))
+	defer fresh.Close()
+	assert.NoError(t, fresh.Import(data))
+	assert.Equal(t, int64(3), fresh.Stats().Sent)
+	// 只保留 WithHistory 的条数
+	assert.Equal(t, [][]interface{}{{float64(3)}}, fresh.history.last("add", 10))
+
+	// 没有开启历史时忽略
+	plain := New()
+	defer plain.Close()
+	assert.NoError(t, plain.Import(data))
+	assert.Nil(t, plain.history)
+}
@@ -0,0 +1,89 @@
+package eventbus
+
+import "time"
+
+// Subscription 订阅句柄，用于定位同一事件下的某个具体订阅
+type Subscription struct {
+	Key string
+	ID  uint64
+}
+
+// SubscribeOption 订阅选项
+type SubscribeOption func(e *event)
+
+// WithAdapter 调用前使用 adapter 转换发送的入参，订阅的参数形式可以与发送方不同
+// 转换后的参数个数不匹配时本次调用返回 ErrArgsNotMatch
+func WithAdapter(adapter func(sendArgs []interface{}) []interface{}) SubscribeOption {
+	return func(e *event) {
+		e.adapter = adapter
+	}
+}
+
+// Subscribe 注册订阅器并返回订阅句柄，同一事件允许注册多个订阅，Send 时按注册顺序依次调用
+func (p *EventBus) Subscribe(eventKey string, call interface{}, opts ...SubscribeOption) (Subscription, error) {
+	e := &event{key: eventKey, call: call}
+	for _, opt := range opts {
+		opt(e)
+	}
+	if err := p.on(e, false); err != nil {
+		return Subscription{}, err
+	}
+	return Subscription{Key: eventKey, ID: e.id}, nil
+}
+
+// Unsubscribe 移除句柄对应的订阅，Freeze 之后调用无效
+func (p *EventBus) Unsubscribe(handle Subscription) {
+	if p.isFrozen() {
+		return
+	}
+	p.removeIf(handle.Key, func(e *event) bool {
+		return e.id == handle.ID
+	})
+}
+
+// SendTo 只调用句柄对应的订阅，不会扇出到同一事件下的其他订阅
+func (p *EventBus) SendTo(handle Subscription, args ...interface{}) (err error) {
+	defer func() { p.sendError(handle.Key, err) }()
+	if p.isClosed() {
+		return ErrBusClosed
+	}
+	e := p.lookup(handle)
+	if e == nil {
+		return ErrNotFound
+	}
+	if !e.accept(args, p.ignoreExtraArgs) {
+		return ErrArgsNotMatch
+	}
+	if err := p.acquire(); err != nil {
+		return err
+	}
+	sent, err := claimOnce([]*event{e})
+	if err != nil {
+		p.release()
+		return err
+	}
+	p.removeOnce(sent)
+	return p.enqueue(&sender{key: handle.Key, events: sent, args: args})
+}
+
+// SubscriptionAge 返回句柄对应的订阅从注册到现在的时长，订阅不存在时返回 0
+// 用于发现长期存在或泄漏的订阅，时间使用 WithClock 设置的时钟
+func (p *EventBus) SubscriptionAge(handle Subscription) time.Duration {
+	e := p.lookup(handle)
+	if e == nil {
+		return 0
+	}
+	return p.clock.Now().Sub(e.registered)
+}
+
+// lookup 查找句柄对应的订阅
+func (p *EventBus) lookup(handle Subscription) *event {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	for _, e := range p.handlers(handle.Key) {
+		if e.id == handle.ID {
+			return e
+		}
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package eventbus
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventBus_Subscribe(t *testing.T) {
+	events := New()
+	defer events.Close()
+
+	got := make(chan int, 3)
+	for i := 1; i <= 3; i++ {
+		i := i
+		_, err := events.Subscribe("fanout", func(a int) {
+			got <- a * i
+		})
+		assert.NoError(t, err)
+	}
+
+	err := events.Send("fanout", 1)
+	assert.NoError(t, err)
+	// 同一次发送内按注册顺序依次调用
+	for i := 1; i <= 3; i++ {
+		select {
+		case v := <-got:
+			assert.Equal(t, i, v)
+		case <-time.After(time.Second):
+			t.Fatal("event not received")
+		}
+	}
+
+	// 同一事件已经存在订阅，On 继续追加订阅
+	assert.NoError(t, events.On("fanout", func(a int) {}))
+}
+
+func TestEventBus_SendTo(t *testing.T) {
+	events := New()
+	defer events.Close()
+
+	got := make(chan int, 3)
+	var handles []Subscription
+	for i := 1; i <= 3; i++ {
+		i := i
+		handle, err := events.Subscribe("reply", func(a int) {
+			got <- i
+		})
+		assert.NoError(t, err)
+		handles = append(handles, handle)
+	}
+
+	err := events.SendTo(handles[1], 1)
+	assert.NoError(t, err)
+	select {
+	case v := <-got:
+		assert.Equal(t, 2, v)
+	case <-time.After(time.Second):
+		t.Fatal("event not received")
+	}
+	select {
+	case v := <-got:
+		t.Fatalf("unexpected handler %d called", v)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	err = events.SendTo(handles[1], 1, 2)
+	assert.EqualError(t, err, ErrArgsNotMatch.Error())
+
+	events.Unsubscribe(handles[1])
+	err = events.SendTo(handles[1], 1)
+	assert.EqualError(t, err, ErrNotFound.Error())
+	err = events.SendTo(handles[0], 1)
+	assert.NoError(t, err)
+}
+
+func TestEventBus_SubscribeWithAdapter(t *testing.T) {
+	events := New()
+	defer events.Close()
+
+	type result struct {
+		sum   int
+		label string
+	}
+	got := make(chan result, 1)
+	// 发送方的参数是 (label, a, b)，订阅需要 (sum, label)
+	_, err := events.Subscribe("add", func(sum int, label string) {
+		got <- result{sum, label}
+	}, WithAdapter(func(args []interface{}) []interface{} {
+		return []interface{}{args[1].(int) + args[2].(int), args[0]}
+	}))
+	assert.NoError(t, err)
+
+	err = events.Send("add", "total", 1, 2)
+	assert.NoError(t, err)
+	select {
+	case r := <-got:
+		assert.Equal(t, result{3, "total"}, r)
+	case <-time.After(time.Second):
+		t.Fatal("event not received")
+	}
+
+	// 转换后的参数个数不匹配
+	e := events.handlers("add")[0]
+	err = e.Call([]interface{}{"total", 1, 2, 3})
+	assert.NoError(t, err)
+	e.adapter = func(args []interface{}) []interface{} { return args }
+	err = e.Call([]interface{}{"total", 1, 2})
+	assert.EqualError(t, err, ErrArgsNotMatch.Error())
+}
+
+func TestEventBus_StopPropagation(t *testing.T) {
+	events := New()
+	defer events.Close()
+
+	var called []int
+	failed := errors.New("failed")
+	events.Subscribe("chain", func() error {
+		called = append(called, 1)
+		return failed
+	})
+	events.Subscribe("chain", func() error {
+		called = append(called, 2)
+		return StopPropagation
+	})
+	events.Subscribe("chain", func() error {
+		called = append(called, 3)
+		return nil
+	})
+
+	// 同步执行一次发送，普通错误不会中断，StopPropagation 中断后续订阅
+	s := &sender{key: "chain", events: events.handlers("chain")}
+	err := s.Call()
+	assert.Equal(t, failed, err)
+	assert.Equal(t, []int{1, 2}, called)
+}
+
+func TestEventBus_SubscriptionAge(t *testing.T) {
+	clock := NewFakeClock(time.Unix(100, 0))
+	events := New(WithClock(clock))
+	defer events.Close()
+	handle, err := events.Subscribe("job", func() {})
+	assert.NoError(t, err)
+	assert.Equal(t, time.Duration(0), events.SubscriptionAge(handle))
+
+	clock.Advance(time.Minute)
+	assert.Equal(t, time.Minute, events.SubscriptionAge(handle))
+	clock.Advance(time.Hour)
+	assert.Equal(t, time.Hour+time.Minute, events.SubscriptionAge(handle))
+	if infos := events.Snapshot(); assert.Len(t, infos, 1) {
+		assert.Equal(t, time.Unix(100, 0), infos[0].Registered)
+	}
+
+	events.Unsubscribe(handle)
+	assert.Equal(t, time.Duration(0), events.SubscriptionAge(handle))
+}
@@ -0,0 +1,124 @@
+package eventbus
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// maxSyncDepth 同步发送的最大嵌套层数
+const maxSyncDepth = 64
+
+type syncKey struct{}
+
+// a syncState 同步发送的嵌套层数和当前协程持有的事件锁
+// 同一条同步调用链在同一个协程中执行，held 在各层之间共享
+type syncState struct {
+	depth int
+	held  map[*serialMutex]bool
+}
+
+// SendSync 在当前协程中依次调用事件的全部订阅，返回第一个订阅错误
+// 订阅中继续同步发送时使用 SendSyncCtx 并传入注入的 context，以便识别嵌套和已经持有的事件锁
+func (p *EventBus) SendSync(eventKey string, args ...interface{}) error {
+	return p.SendSyncCtx(context.Background(), eventKey, args...)
+}
+
+// SendSyncCtx 携带 context 同步调用事件。在订阅中传入注入的 context 嵌套发送时，
+// 同一事件的 OnSerialized 锁可以重入，不会自我死锁；嵌套超过 64 层返回 ErrRecursionDepth
+func (p *EventBus) SendSyncCtx(ctx context.Context, eventKey string, args ...interface{}) error {
+	state, err := newSyncState(ctx)
+	if err != nil {
+		return err
+	}
+	s := &sender{key: eventKey, args: args, ctx: ctx, sync: state}
+	p.inherit(s)
+	return p.sendSync(s)
+}
+
+// newSyncState 构建同步发送的状态，ctx 属于同步发送时继承嵌套层数和已经持有的锁
+func newSyncState(ctx context.Context) (*syncState, error) {
+	state := &syncState{depth: 1, held: map[*serialMutex]bool{}}
+	if parent := syncStateOf(ctx); parent != nil {
+		state.depth = parent.depth + 1
+		state.held = parent.held
+	}
+	if state.depth > maxSyncDepth {
+		return nil, ErrRecursionDepth
+	}
+	return state, nil
+}
+
+// sendSync 查找订阅后在当前协程中执行
+func (p *EventBus) sendSync(s *sender) (err error) {
+	defer func() { p.sendError(s.key, err) }()
+	if p.isClosed() {
+		return ErrBusClosed
+	}
+	if target := p.federation.target(s.key); target != nil && target != p {
+		return target.sendSync(s)
+	}
+	if dup, err := p.prepare(s); dup || err != nil {
+		return err
+	}
+	return p.callSync(s)
+}
+
+// callSync 在当前协程中执行已经查找到订阅的 s 并更新统计，已经分配发送ID的 s 视为已经计数
+func (p *EventBus) callSync(s *sender) error {
+	if s.id == 0 {
+		s.id = atomic.AddUint64(&p.sendSeq, 1)
+		atomic.AddInt64(&p.stats.sent, 1)
+	}
+	end := p.begin(s)
+	err := s.Call()
+	end()
+	if err != nil {
+		atomic.AddInt64(&p.stats.failed, 1)
+	}
+	p.recordOutcome(s.key, err)
+	return err
+}
+
+// SyncDepth 返回 ctx 所属同步发送的嵌套层数，不在同步发送中时返回 0
+func SyncDepth(ctx context.Context) int {
+	if state := syncStateOf(ctx); state != nil {
+		return state.depth
+	}
+	return 0
+}
+
+func syncStateOf(ctx context.Context) *syncState {
+	if ctx == nil {
+		return nil
+	}
+	state, _ := ctx.Value(syncKey{}).(*syncState)
+	return state
+}
+
+// runSerial 调用订阅，OnSerialized 的订阅持有事件锁，同步调用链中已经持有的锁不再获取
+func (s *sender) runSerial(e *event, d *dispatch) error {
+	return s.serialize(e, func() error {
+		return e.run(d, s.args)
+	})
+}
+
+// serialize 执行订阅 e 的调用 fn，e 是 OnSerialized 的订阅时持有事件锁，中间件在锁内执行
+func (s *sender) serialize(e *event, fn func() error) error {
+	if e.bus != nil {
+		fn = e.bus.wrap(s.key, e, s.args, fn)
+	}
+	if e.serial == nil || (s.sync != nil && s.sync.held[e.serial]) {
+		return fn()
+	}
+	if e.serial.ownedByCaller() {
+		// 当前协程已经持有锁，但不在同一条同步调用链中，继续加锁会死锁
+		return ErrReentrantDeadlock
+	}
+	e.serial.Lock()
+	defer e.serial.Unlock()
+	if s.sync != nil {
+		s.sync.held[e.serial] = true
+		defer delete(s.sync.held, e.serial)
+	}
+	return fn()
+}
@@ -0,0 +1,71 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventBus_SendSync(t *testing.T) {
+	events := New()
+	defer events.Close()
+	failed := errors.New("failed")
+	var order []string
+	assert.NoError(t, events.On("order", func(ctx context.Context, id string) error {
+		order = append(order, "order:"+id)
+		assert.Equal(t, 1, SyncDepth(ctx))
+		return events.SendSyncCtx(ctx, "notify", id)
+	}))
+	assert.NoError(t, events.On("notify", func(ctx context.Context, id string) error {
+		order = append(order, "notify:"+id)
+		assert.Equal(t, 2, SyncDepth(ctx))
+		return failed
+	}))
+
+	// 同步调用，返回时订阅已经执行完
+	assert.Equal(t, failed, events.SendSync("order", "1"))
+	assert.Equal(t, []string{"order:1", "notify:1"}, order)
+	assert.Equal(t, ErrNotFound, events.SendSync("missing"))
+}
+
+func TestEventBus_SendSyncReentrant(t *testing.T) {
+	events := New()
+	defer events.Close()
+	var visited []int
+	// 同一事件的串行锁在同步调用链中可以重入
+	assert.NoError(t, events.OnSerialized("countdown", func(ctx context.Context, n int) error {
+		visited = append(visited, n)
+		if n == 0 {
+			return nil
+		}
+		return events.SendSyncCtx(ctx, "countdown", n-1)
+	}))
+	assert.NoError(t, events.SendSync("countdown", 3))
+	assert.Equal(t, []int{3, 2, 1, 0}, visited)
+
+	// 串行锁在调用结束后释放，异步发送依旧互斥
+	assert.NoError(t, events.Send("countdown", 0))
+}
+
+func TestEventBus_SendSyncDepth(t *testing.T) {
+	events := New()
+	defer events.Close()
+	calls := 0
+	assert.NoError(t, events.On("loop", func(ctx context.Context) error {
+		calls++
+		return events.SendSyncCtx(ctx, "loop")
+	}))
+	assert.Equal(t, ErrRecursionDepth, events.SendSync("loop"))
+	assert.Equal(t, maxSyncDepth, calls)
+
+	// 异步发送的 context 不继承同步调用链
+	got := make(chan int, 1)
+	assert.NoError(t, events.On("async", func(ctx context.Context) { got <- SyncDepth(ctx) }))
+	assert.NoError(t, events.On("start", func(ctx context.Context) error {
+		return events.SendCtx(ctx, "async")
+	}))
+	assert.NoError(t, events.SendSync("start"))
+	assert.Equal(t, 0, <-got)
+}
@@ -0,0 +1,97 @@
+package eventbus
+
+import (
+	"testing"
+	"time"
+)
+
+// AssertEmitted 注册临时监听后执行 action，如果 within 内事件没有被调用则测试失败
+// 监听在返回前移除。事件调用是异步的，该方法省去了下游测试中的等待代码
+func AssertEmitted(t testing.TB, bus *EventBus, eventKey string, within time.Duration, action func()) bool {
+	t.Helper()
+	fired, stop, err := bus.spy(eventKey)
+	if err != nil {
+		t.Errorf("spy event %s: %s", eventKey, err)
+		return false
+	}
+	defer stop()
+	action()
+	select {
+	case <-fired:
+		return true
+	case <-time.After(within):
+		t.Errorf("event %s not emitted within %s", eventKey, within)
+		return false
+	}
+}
+
+// AssertNotEmitted 注册临时监听后执行 action，如果 within 内事件被调用则测试失败
+func AssertNotEmitted(t testing.TB, bus *EventBus, eventKey string, within time.Duration, action func()) bool {
+	t.Helper()
+	fired, stop, err := bus.spy(eventKey)
+	if err != nil {
+		t.Errorf("spy event %s: %s", eventKey, err)
+		return false
+	}
+	defer stop()
+	action()
+	select {
+	case <-fired:
+		t.Errorf("event %s emitted within %s", eventKey, within)
+		return false
+	case <-time.After(within):
+		return true
+	}
+}
+
+// spy 注册一个接受任意入参的临时订阅，事件被调用时 fired 收到信号，stop 移除该订阅
+func (p *EventBus) spy(eventKey string) (fired <-chan struct{}, stop func(), err error) {
+	ch := make(chan struct{}, 1)
+	e := &event{key: eventKey, raw: func(args []interface{}) error {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+		return nil
+	}}
+	if err = p.on(e, false); err != nil {
+		return nil, nil, err
+	}
+	stop = func() {
+		p.removeIf(eventKey, func(h *event) bool {
+			return h == e
+		})
+	}
+	return ch, stop, nil
+}
+
+// Harness 多个订阅器的集成测试工具，全部订阅器共享同一个 FakeClock，
+// Advance 时所有订阅器的定时器一起按时间顺序触发
+type Harness struct {
+	Clock *FakeClock
+	buses []*EventBus
+}
+
+// NewHarness 构建一个时钟从 start 开始的 Harness
+func NewHarness(start time.Time) *Harness {
+	return &Harness{Clock: NewFakeClock(start)}
+}
+
+// Bus 构建一个使用共享时钟的订阅器，Close 时一并关闭
+func (h *Harness) Bus(opts ...Option) *EventBus {
+	bus := New(append(opts[:len(opts):len(opts)], WithClock(h.Clock))...)
+	h.buses = append(h.buses, bus)
+	return bus
+}
+
+// Advance 推进共享时钟，触发全部订阅器中到期的定时器
+func (h *Harness) Advance(d time.Duration) {
+	h.Clock.Advance(d)
+}
+
+// Close 关闭 Harness 创建的全部订阅器
+func (h *Harness) Close() {
+	for _, bus := range h.buses {
+		bus.Close()
+	}
+}
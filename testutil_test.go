@@ -0,0 +1,72 @@
+package eventbus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// recordTB 记录断言失败而不终止外层测试
+type recordTB struct {
+	testing.TB
+	failed bool
+}
+
+func (r *recordTB) Helper() {}
+
+func (r *recordTB) Errorf(format string, args ...interface{}) {
+	r.failed = true
+}
+
+func TestAssertEmitted(t *testing.T) {
+	events := New()
+	defer events.Close()
+	events.On("add", func(a, b int) {})
+
+	ok := AssertEmitted(t, events, "add", time.Second, func() {
+		events.Send("add", 1, 2)
+	})
+	assert.True(t, ok)
+	// 临时监听已经移除
+	assert.Len(t, events.handlers("add"), 1)
+
+	rec := &recordTB{}
+	ok = AssertEmitted(rec, events, "add", 20*time.Millisecond, func() {})
+	assert.False(t, ok)
+	assert.True(t, rec.failed)
+}
+
+func TestAssertNotEmitted(t *testing.T) {
+	events := New()
+	defer events.Close()
+	events.On("add", func(a, b int) {})
+
+	ok := AssertNotEmitted(t, events, "add", 20*time.Millisecond, func() {})
+	assert.True(t, ok)
+
+	rec := &recordTB{}
+	ok = AssertNotEmitted(rec, events, "add", time.Second, func() {
+		events.Send("add", 1, 2)
+	})
+	assert.False(t, ok)
+	assert.True(t, rec.failed)
+	assert.Len(t, events.handlers("add"), 1)
+}
+
+func TestHarness(t *testing.T) {
+	h := NewHarness(time.Unix(0, 0))
+	a, b := h.Bus(), h.Bus()
+	got := make(chan string, 2)
+	assert.NoError(t, a.On("a.tick", func() { got <- "a" }))
+	assert.NoError(t, b.On("b.tick", func() { got <- "b" }))
+	assert.NoError(t, a.SendAfter(time.Second, "a.tick"))
+	assert.NoError(t, b.SendAfter(2*time.Second, "b.tick"))
+
+	h.Advance(time.Second)
+	assert.Equal(t, "a", <-got)
+	h.Advance(time.Second)
+	assert.Equal(t, "b", <-got)
+	h.Close()
+	assert.Equal(t, time.Unix(2, 0), a.clock.Now())
+}
@@ -0,0 +1,36 @@
+package eventbus
+
+import (
+	"context"
+	"time"
+)
+
+// SendWithTimeout 同 SendSync，最多等待 d，超时返回 ErrHandlerTimeout
+// 超时后不再等待仍在执行的订阅，注入订阅的 context 被取消，订阅可以据此提前结束
+func (p *EventBus) SendWithTimeout(d time.Duration, eventKey string, args ...interface{}) (err error) {
+	if target := p.federation.target(eventKey); target != nil && target != p {
+		return target.SendWithTimeout(d, eventKey, args...)
+	}
+	defer func() { p.sendError(eventKey, err) }()
+	if p.isClosed() {
+		return ErrBusClosed
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	state, _ := newSyncState(ctx)
+	s := &sender{key: eventKey, args: args, ctx: ctx, sync: state}
+	if dup, err := p.prepare(s); dup || err != nil {
+		return err
+	}
+	done := make(chan error, 1)
+	go func() { done <- p.callSync(s) }()
+	expired := make(chan struct{})
+	timer := p.clock.AfterFunc(d, func() { close(expired) })
+	defer timer.Stop()
+	select {
+	case err := <-done:
+		return err
+	case <-expired:
+		return ErrHandlerTimeout
+	}
+}
@@ -0,0 +1,29 @@
+package eventbus
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventBus_SendWithTimeout(t *testing.T) {
+	events := New()
+	defer events.Close()
+	cancelled := make(chan struct{})
+	assert.NoError(t, events.On("slow", func(ctx context.Context) {
+		select {
+		case <-ctx.Done():
+			close(cancelled)
+		case <-time.After(time.Second):
+		}
+	}))
+	assert.NoError(t, events.On("fast", func() error { return nil }))
+
+	assert.Equal(t, ErrHandlerTimeout, events.SendWithTimeout(10*time.Millisecond, "slow"))
+	// 超时后订阅收到取消
+	<-cancelled
+	assert.NoError(t, events.SendWithTimeout(time.Second, "fast"))
+	assert.Equal(t, ErrNotFound, events.SendWithTimeout(time.Second, "missing"))
+}
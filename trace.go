@@ -0,0 +1,37 @@
+package eventbus
+
+import "time"
+
+// Trace 在接下来的 d 时间内输出 eventKey 每次调用的详细信息(入参、订阅、耗时、结果)，到期后自动停止
+// 用于在线上针对单个事件排查问题，重复调用会重置截止时间
+func (p *EventBus) Trace(eventKey string, d time.Duration) {
+	p.tmu.Lock()
+	defer p.tmu.Unlock()
+	if p.traces == nil {
+		p.traces = make(map[string]time.Time)
+	}
+	p.traces[eventKey] = time.Now().Add(d)
+}
+
+// tracing 判断 eventKey 是否处于跟踪中，过期的跟踪会被清除
+func (p *EventBus) tracing(eventKey string) bool {
+	p.tmu.Lock()
+	defer p.tmu.Unlock()
+	deadline, ok := p.traces[eventKey]
+	if ok && !time.Now().Before(deadline) {
+		delete(p.traces, eventKey)
+		return false
+	}
+	return ok
+}
+
+// traceSender 跟踪中的发送在每个订阅调用结束后输出日志
+func (p *EventBus) traceSender(s *sender) {
+	if !p.tracing(s.key) {
+		return
+	}
+	s.trace = func(e *event, cost time.Duration, err error) {
+		p.logger.Printf("[TRACE] send %d %s args: %v handler: %d cost: %s err: %v",
+			s.id, s.key, s.args, e.id, cost, err)
+	}
+}
@@ -0,0 +1,65 @@
+package eventbus
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// recordLogger 记录输出的日志
+type recordLogger struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+func (l *recordLogger) Printf(format string, args ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.lines = append(l.lines, fmt.Sprintf(format, args...))
+}
+
+func (l *recordLogger) Lines() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string(nil), l.lines...)
+}
+
+func TestEventBus_Trace(t *testing.T) {
+	logger := &recordLogger{}
+	events := New(WithLogger(logger))
+	done := make(chan struct{}, 4)
+	handler := func(n int) { done <- struct{}{} }
+	assert.NoError(t, events.On("a", handler))
+	assert.NoError(t, events.On("b", handler))
+
+	events.Trace("a", 50*time.Millisecond)
+	assert.NoError(t, events.Send("a", 1))
+	assert.NoError(t, events.Send("b", 2))
+	<-done
+	<-done
+	assert.Eventually(t, func() bool { return len(logger.Lines()) == 1 }, time.Second, time.Millisecond)
+
+	// 到期后不再输出
+	time.Sleep(60 * time.Millisecond)
+	assert.NoError(t, events.Send("a", 3))
+	<-done
+	events.Close()
+
+	lines := logger.Lines()
+	if assert.Len(t, lines, 1) {
+		assert.True(t, strings.HasPrefix(lines[0], "[TRACE] send 1 a args: [1]"), lines[0])
+	}
+}
+
+func TestPanicLogger(t *testing.T) {
+	logger := &recordLogger{}
+	events := New(WithLogger(logger))
+	assert.NoError(t, events.On("boom", func() { panic("boom") }))
+	assert.ErrorIs(t, events.SendSync("boom"), ErrRuntimePanic)
+	events.Close()
+	assert.Equal(t, []string{"[PANIC RECOVER] call boom panic: boom"}, logger.Lines())
+}
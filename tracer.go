@@ -0,0 +1,75 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+)
+
+// Tracer 分布式追踪的接入点，通过很薄的适配即可使用 OpenTelemetry 的 trace.Tracer
+type Tracer interface {
+	// Start 以 name 开始一个 span，返回携带该 span 的 context
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span Tracer 开始的 span
+type Span interface {
+	// AddEvent 记录一个事件，每个订阅调用结束后记录订阅ID和耗时
+	AddEvent(name string, attributes map[string]interface{})
+	// RecordError 记录订阅返回的错误，panic 时为 *PanicError
+	RecordError(err error)
+	End()
+}
+
+// WithTracer 每次发送开始一个以事件命名的 span，订阅注入的 context 携带该 span，
+// 每个订阅的耗时作为 span 的事件记录，订阅返回错误或 panic 时记录为 span 的错误，全部订阅结束后结束 span
+func WithTracer(tracer Tracer) Option {
+	return func(p *EventBus) {
+		p.tracer = tracer
+	}
+}
+
+// WithTraceSampling 配合 WithTracer 使用，只有比例为 rate(0 到 1)的发送开始 span，降低高吞吐场景下的开销
+// 采样结果随订阅注入的 context 传递，订阅中继续发送的事件沿用触发事件的采样结果
+func WithTraceSampling(rate float64) Option {
+	return func(p *EventBus) {
+		p.sampled = func() bool { return rand.Float64() < rate }
+	}
+}
+
+type sampledKey struct{}
+
+// startSpan 开启追踪时为本次发送开始 span，订阅通过 d 得到携带 span 的 context
+// 没有被采样的发送不开始 span，采样结果记录在 context 中
+func (s *sender) startSpan(d *dispatch) Span {
+	if s.tracer == nil {
+		return nil
+	}
+	parent := d.parent
+	if parent == nil {
+		parent = context.Background()
+	}
+	if s.sampled != nil {
+		sampled, ok := parent.Value(sampledKey{}).(bool)
+		if !ok {
+			sampled = s.sampled()
+			parent = context.WithValue(parent, sampledKey{}, sampled)
+			d.parent = parent
+		}
+		if !sampled {
+			return nil
+		}
+	}
+	ctx, span := s.tracer.Start(parent, s.key)
+	d.parent = ctx
+	return span
+}
+
+// recordSpan 记录一个订阅的调用
+func recordSpan(span Span, e *event, cost time.Duration, err error) {
+	span.AddEvent("handler", map[string]interface{}{"handler.id": e.id, "duration": cost})
+	if err != nil && !errors.Is(err, StopPropagation) {
+		span.RecordError(err)
+	}
+}
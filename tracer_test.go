@@ -0,0 +1,92 @@
+package eventbus
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type spanKey struct{}
+
+type recordingSpan struct {
+	name     string
+	handlers int
+	errs     []error
+	ended    bool
+}
+
+func (s *recordingSpan) AddEvent(name string, attributes map[string]interface{}) {
+	s.handlers++
+}
+
+func (s *recordingSpan) RecordError(err error) {
+	s.errs = append(s.errs, err)
+}
+
+func (s *recordingSpan) End() {
+	s.ended = true
+}
+
+type recordingTracer struct {
+	mu    sync.Mutex
+	spans []*recordingSpan
+}
+
+func (t *recordingTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	span := &recordingSpan{name: name}
+	t.mu.Lock()
+	t.spans = append(t.spans, span)
+	t.mu.Unlock()
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+func TestEventBus_WithTracer(t *testing.T) {
+	tracer := &recordingTracer{}
+	events := New(WithTracer(tracer))
+	defer events.Close()
+	var inHandler Span
+	assert.NoError(t, events.On("order", func(ctx context.Context) {
+		inHandler, _ = ctx.Value(spanKey{}).(Span)
+	}))
+	assert.NoError(t, events.On("order", func() { panic("boom") }))
+
+	assert.ErrorIs(t, events.SendSync("order"), ErrRuntimePanic)
+	assert.NoError(t, events.On("ping", func() {}))
+	assert.NoError(t, events.SendSync("ping"))
+
+	if assert.Len(t, tracer.spans, 2) {
+		span := tracer.spans[0]
+		assert.Equal(t, "order", span.name)
+		assert.Equal(t, Span(span), inHandler)
+		assert.Equal(t, 2, span.handlers)
+		if assert.Len(t, span.errs, 1) {
+			assert.ErrorIs(t, span.errs[0], ErrRuntimePanic)
+		}
+		assert.True(t, span.ended)
+		assert.Equal(t, "ping", tracer.spans[1].name)
+		assert.Empty(t, tracer.spans[1].errs)
+	}
+}
+
+func TestEventBus_WithTraceSampling(t *testing.T) {
+	tracer := &recordingTracer{}
+	events := New(WithTracer(tracer), WithTraceSampling(0.25))
+	defer events.Close()
+	assert.NoError(t, events.On("parent", func(ctx context.Context) error {
+		return events.SendSyncCtx(ctx, "child")
+	}))
+	assert.NoError(t, events.On("child", func() {}))
+	const sends = 2000
+	for i := 0; i < sends; i++ {
+		assert.NoError(t, events.SendSync("parent"))
+	}
+	count := map[string]int{}
+	for _, span := range tracer.spans {
+		count[span.name]++
+	}
+	assert.InDelta(t, sends/4, count["parent"], sends/10)
+	// 子事件沿用父事件的采样结果
+	assert.Equal(t, count["parent"], count["child"])
+}
@@ -0,0 +1,20 @@
+package eventbus
+
+import "time"
+
+// OnWithTTL 注册订阅器，订阅超过 ttl 没有被调用时自动移除，每次调用重新计时
+// 用于清理动态系统中不再活跃的订阅，自动移除不受 Freeze 限制
+func (p *EventBus) OnWithTTL(eventKey string, ttl time.Duration, call interface{}) error {
+	e := &event{key: eventKey, call: call}
+	timer := p.clock.AfterFunc(ttl, func() {
+		p.removeIf(eventKey, func(h *event) bool { return h == e })
+	})
+	// 注册完成前不会过期
+	timer.Stop()
+	e.touch = func() { timer.Reset(ttl) }
+	if err := p.on(e, true); err != nil {
+		return err
+	}
+	timer.Reset(ttl)
+	return nil
+}
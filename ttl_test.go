@@ -0,0 +1,26 @@
+package eventbus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventBus_OnWithTTL(t *testing.T) {
+	events := New()
+	defer events.Close()
+	noop := func() {}
+	assert.NoError(t, events.OnWithTTL("idle", 30*time.Millisecond, noop))
+	assert.NoError(t, events.OnWithTTL("active", 30*time.Millisecond, noop))
+
+	// 持续调用的订阅一直保留
+	for i := 0; i < 6; i++ {
+		time.Sleep(10 * time.Millisecond)
+		assert.NoError(t, events.Send("active"))
+	}
+	assert.Eventually(t, func() bool {
+		return len(events.handlers("idle")) == 0
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, []string{"active"}, events.Keys())
+}
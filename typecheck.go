@@ -0,0 +1,45 @@
+package eventbus
+
+import "reflect"
+
+// WithArgTypeCheck 发送时校验入参类型，与订阅的参数类型不符时返回 ErrEventType，不再在调用时 panic
+// 参数类型在注册时计算并缓存在订阅上，发送时只是遍历比较，nil 入参视为参数类型的零值
+// 经过 adapter 或结构体转换的入参、原始订阅、通配和兜底订阅不校验
+func WithArgTypeCheck() Option {
+	return func(p *EventBus) {
+		p.argTypeCheck = true
+	}
+}
+
+// paramTypes 返回调用方法 t 从 start 开始的参数类型，可变参数使用元素类型
+func paramTypes(t reflect.Type, start int) []reflect.Type {
+	params := make([]reflect.Type, 0, t.NumIn()-start)
+	for i := start; i < t.NumIn(); i++ {
+		params = append(params, paramType(t, i))
+	}
+	return params
+}
+
+// typesMatch 使用缓存的参数类型校验入参，没有缓存或入参需要转换时返回 true
+func (e *event) typesMatch(args []interface{}) bool {
+	if e.params == nil || e.adapter != nil || !e.fits(args, true) {
+		return true
+	}
+	for i, arg := range args {
+		if i >= len(e.params) && !e.variadic {
+			// 多余的入参被忽略
+			break
+		}
+		if arg == nil {
+			continue
+		}
+		param := e.params[len(e.params)-1]
+		if i < len(e.params) {
+			param = e.params[i]
+		}
+		if !reflect.TypeOf(arg).AssignableTo(param) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,38 @@
+package eventbus
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventBus_WithArgTypeCheck(t *testing.T) {
+	events := New(WithArgTypeCheck())
+	defer events.Close()
+	assert.NoError(t, events.On("add", func(ctx context.Context, a int, rest ...string) {}))
+	assert.NoError(t, events.SendSync("add", 1))
+	assert.NoError(t, events.SendSync("add", 1, "a", "b"))
+	assert.Equal(t, ErrEventType, events.SendSync("add", "1"))
+	assert.Equal(t, ErrEventType, events.SendSync("add", 1, "a", 2))
+	// 接口类型的参数接受实现了它的入参，nil 使用零值
+	assert.NoError(t, events.On("fail", func(err error) {}))
+	assert.NoError(t, events.SendSync("fail", ErrNotFound))
+	assert.NoError(t, events.SendSync("fail", nil))
+
+	// 替换订阅后使用新订阅的参数类型
+	assert.NoError(t, events.ReplaceAll(map[string]interface{}{"add": func(a string) {}}))
+	assert.NoError(t, events.SendSync("add", "1"))
+	assert.Equal(t, ErrEventType, events.SendSync("add", 1))
+}
+
+func BenchmarkArgTypeCheck(b *testing.B) {
+	events := New(WithArgTypeCheck())
+	defer events.Close()
+	events.On("add", func(a, b int, s string) {})
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		events.SendSync("add", 1, 2, "a")
+	}
+}
@@ -0,0 +1,30 @@
+package eventbus
+
+import "reflect"
+
+// TypedKey 返回 payload 类型对应的事件，即包的导入路径加类型名，如 github.com/app/order.OrderCreated
+// 指针按指向的类型计算，未命名的类型使用类型的字符串形式
+func TypedKey(payload interface{}) string {
+	t := reflect.TypeOf(payload)
+	if t == nil {
+		return ""
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Name() == "" || t.PkgPath() == "" {
+		return t.String()
+	}
+	return t.PkgPath() + "." + t.Name()
+}
+
+// OnTyped 以 payload 的类型作为事件注册订阅，payload 只用于计算事件，可以传入零值
+// 调用方法接收 PublishTyped 发送的 payload，同一类型允许注册多个订阅
+func (p *EventBus) OnTyped(payload interface{}, call interface{}) error {
+	return p.on(&event{key: TypedKey(payload), call: call}, false)
+}
+
+// PublishTyped 以 payload 的类型作为事件发送 payload，发送方无需维护字符串形式的事件
+func (p *EventBus) PublishTyped(payload interface{}) error {
+	return p.Send(TypedKey(payload), payload)
+}
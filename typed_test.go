@@ -0,0 +1,32 @@
+package eventbus
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type typedOrder struct {
+	ID int
+}
+
+func TestTypedKey(t *testing.T) {
+	// 不依赖模块路径
+	want := reflect.TypeOf(typedOrder{}).PkgPath() + ".typedOrder"
+	assert.Equal(t, want, TypedKey(typedOrder{}))
+	assert.Equal(t, want, TypedKey(&typedOrder{}))
+	assert.Equal(t, "int", TypedKey(1))
+	assert.Equal(t, "[]string", TypedKey([]string{}))
+	assert.Equal(t, "", TypedKey(nil))
+}
+
+func TestEventBus_PublishTyped(t *testing.T) {
+	events := New()
+	got := make(chan typedOrder, 1)
+	assert.NoError(t, events.OnTyped(typedOrder{}, func(o typedOrder) { got <- o }))
+	assert.NoError(t, events.PublishTyped(typedOrder{ID: 7}))
+	events.Close()
+	assert.Equal(t, typedOrder{ID: 7}, <-got)
+	assert.Equal(t, []string{TypedKey(typedOrder{})}, events.Keys())
+}
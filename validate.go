@@ -0,0 +1,58 @@
+package eventbus
+
+import "reflect"
+
+// WithValidator 发送的入参只有一个结构体(或结构体指针)时，调用前使用 validator 校验，
+// 校验失败时不调用订阅，Send 返回 validator 的错误，可以接入 go-playground/validator 的 Struct 方法
+func WithValidator(validator func(interface{}) error) Option {
+	return func(p *EventBus) {
+		p.validator = validator
+	}
+}
+
+// WithRejectNonDataArgs 入参包含 channel 或 func 时不调用订阅，Send 返回 ErrNonDataArg
+// 默认只在每个事件第一次出现这类入参时输出警告，用于发现数据类事件中误传的入参
+func WithRejectNonDataArgs() Option {
+	return func(p *EventBus) {
+		p.rejectNonData = true
+	}
+}
+
+// checkNonData 校验入参中的 channel 和 func
+func (p *EventBus) checkNonData(eventKey string, args []interface{}) error {
+	for i, arg := range args {
+		if arg == nil {
+			continue
+		}
+		kind := reflect.TypeOf(arg).Kind()
+		if kind != reflect.Chan && kind != reflect.Func {
+			continue
+		}
+		if p.rejectNonData {
+			return ErrNonDataArg
+		}
+		if _, warned := p.nonDataWarned.LoadOrStore(eventKey, true); !warned {
+			p.logger.Printf("[WARN] send %s: arg %d is a %s", eventKey, i, kind)
+		}
+		return nil
+	}
+	return nil
+}
+
+// validate 校验入参，单个结构体入参使用 WithValidator 设置的方法校验
+func (p *EventBus) validate(eventKey string, args []interface{}) error {
+	if err := p.checkNonData(eventKey, args); err != nil {
+		return err
+	}
+	if p.validator == nil || len(args) != 1 || args[0] == nil {
+		return nil
+	}
+	t := reflect.TypeOf(args[0])
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	return p.validator(args[0])
+}
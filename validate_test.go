@@ -0,0 +1,71 @@
+package eventbus
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type signup struct {
+	Email string
+}
+
+func TestWithValidator(t *testing.T) {
+	errEmail := errors.New("email required")
+	events := New(WithValidator(func(v interface{}) error {
+		switch s := v.(type) {
+		case signup:
+			if s.Email == "" {
+				return errEmail
+			}
+		case *signup:
+			if s.Email == "" {
+				return errEmail
+			}
+		}
+		return nil
+	}))
+	var reported []error
+	events.OnSendError(func(eventKey string, err error) {
+		reported = append(reported, err)
+	})
+	called := make(chan string, 4)
+	assert.NoError(t, events.On("signup", func(s signup) { called <- s.Email }))
+	assert.NoError(t, events.On("signupPtr", func(s *signup) { called <- s.Email }))
+	assert.NoError(t, events.On("name", func(s string) { called <- s }))
+
+	assert.Equal(t, errEmail, events.Send("signup", signup{}))
+	assert.Equal(t, errEmail, events.Send("signupPtr", &signup{}))
+	assert.NoError(t, events.Send("signup", signup{Email: "a@b.c"}))
+	// 非结构体入参不校验
+	assert.NoError(t, events.Send("name", ""))
+	events.Close()
+
+	assert.ElementsMatch(t, []string{"a@b.c", ""}, []string{<-called, <-called})
+	assert.Len(t, called, 0)
+	assert.Equal(t, []error{errEmail, errEmail}, reported)
+}
+
+func TestEventBus_NonDataArgs(t *testing.T) {
+	logger := &recordLogger{}
+	events := New(WithLogger(logger))
+	defer events.Close()
+	var got int
+	assert.NoError(t, events.On("job", func(v interface{}) { got++ }))
+
+	// 默认只警告一次，仍然调用订阅
+	assert.NoError(t, events.SendSync("job", make(chan int)))
+	assert.NoError(t, events.SendSync("job", func() {}))
+	assert.NoError(t, events.SendSync("job", 1))
+	assert.Equal(t, 3, got)
+	assert.Equal(t, []string{"[WARN] send job: arg 0 is a chan"}, logger.Lines())
+
+	strict := New(WithRejectNonDataArgs())
+	defer strict.Close()
+	assert.NoError(t, strict.On("job", func(v interface{}) { got++ }))
+	assert.Equal(t, ErrNonDataArg, strict.SendSync("job", make(chan int)))
+	assert.Equal(t, ErrNonDataArg, strict.Send("job", func() {}))
+	assert.NoError(t, strict.SendSync("job", "data"))
+	assert.Equal(t, 4, got)
+}
@@ -0,0 +1,32 @@
+package eventbus
+
+// OnVersion 注册只接收指定版本发送的订阅，同一事件的不同版本可以使用不同的参数形式，
+// 便于滚动升级事件格式。version 为 0 时等同于默认版本，接收 Send 的发送
+func (p *EventBus) OnVersion(eventKey string, version int, call interface{}) error {
+	return p.on(&event{key: eventKey, call: call, version: version}, true)
+}
+
+// SendVersioned 以指定版本发送事件，只调用该版本的订阅，该版本没有订阅时调用默认版本的订阅
+func (p *EventBus) SendVersioned(eventKey string, version int, args ...interface{}) error {
+	return p.send(&sender{key: eventKey, args: args, version: version})
+}
+
+// versioned 筛选指定版本的订阅，全部匹配时返回原切片
+func versioned(handlers []*event, version int) []*event {
+	n := 0
+	for _, e := range handlers {
+		if e.version == version {
+			n++
+		}
+	}
+	if n == len(handlers) {
+		return handlers
+	}
+	matched := make([]*event, 0, n)
+	for _, e := range handlers {
+		if e.version == version {
+			matched = append(matched, e)
+		}
+	}
+	return matched
+}
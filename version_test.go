@@ -0,0 +1,36 @@
+package eventbus
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventBus_SendVersioned(t *testing.T) {
+	events := New(WithSingleHandlerMode())
+	var (
+		mu       sync.Mutex
+		received []string
+	)
+	record := func(s string) {
+		mu.Lock()
+		received = append(received, s)
+		mu.Unlock()
+	}
+	assert.NoError(t, events.On("user", func(name string) { record("v0:" + name) }))
+	assert.NoError(t, events.OnVersion("user", 1, func(name string) { record("v1:" + name) }))
+	assert.NoError(t, events.OnVersion("user", 2, func(name string, age int) { record("v2:" + name) }))
+	assert.EqualError(t, events.OnVersion("user", 2, func() {}), ErrExists.Error())
+
+	assert.NoError(t, events.SendVersioned("user", 1, "a"))
+	assert.NoError(t, events.SendVersioned("user", 2, "b", 18))
+	// v2 的参数形式不同
+	assert.EqualError(t, events.SendVersioned("user", 2, "c"), ErrArgsNotMatch.Error())
+	assert.NoError(t, events.Send("user", "d"))
+	// 未注册的版本使用默认版本
+	assert.NoError(t, events.SendVersioned("user", 3, "e"))
+	events.Close()
+
+	assert.ElementsMatch(t, []string{"v1:a", "v2:b", "v0:d", "v0:e"}, received)
+}
@@ -0,0 +1,181 @@
+package eventbus
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"sort"
+	"sync"
+)
+
+// WithWAL 开启预写日志，异步发送入队前把事件和编码后的入参追加到 path，调用结束后追加确认
+// 重启后使用同一个 path 构建订阅器，注册订阅后调用 ReplayWAL 重新发送尚未确认的事件，实现至少一次投递
+// 入参使用 WithCodec 设置的编解码方式，编码失败的发送不写入日志，仍然正常调用
+func WithWAL(path string) Option {
+	return func(p *EventBus) {
+		p.wal = &wal{path: path}
+	}
+}
+
+// walRecord 预写日志的一行，Data 为空时表示确认 Seq 对应的发送
+type walRecord struct {
+	Seq  uint64 `json:"seq"`
+	Data []byte `json:"data,omitempty"`
+}
+
+// wal 预写日志
+type wal struct {
+	path string
+	mu   sync.Mutex
+	file *os.File
+	seq  uint64
+	// pending 打开时尚未确认的发送，按 Seq 保存编码后的数据
+	pending map[uint64][]byte
+}
+
+// open 读取已有日志中尚未确认的发送，并用它们重写日志，避免文件随重启无限增长
+func (w *wal) open() error {
+	w.pending = make(map[uint64][]byte)
+	if data, err := os.ReadFile(w.path); err == nil {
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		scanner.Buffer(nil, len(data)+1)
+		for scanner.Scan() {
+			var rec walRecord
+			if json.Unmarshal(scanner.Bytes(), &rec) != nil {
+				// 崩溃时写了一半的行
+				continue
+			}
+			if rec.Seq > w.seq {
+				w.seq = rec.Seq
+			}
+			if rec.Data == nil {
+				delete(w.pending, rec.Seq)
+			} else {
+				w.pending[rec.Seq] = rec.Data
+			}
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+	file, err := os.OpenFile(w.path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	w.file = file
+	for _, seq := range w.sortedPending() {
+		if err := w.write(walRecord{Seq: seq, Data: w.pending[seq]}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sortedPending 按写入顺序返回尚未确认的发送
+func (w *wal) sortedPending() []uint64 {
+	seqs := make([]uint64, 0, len(w.pending))
+	for seq := range w.pending {
+		seqs = append(seqs, seq)
+	}
+	sort.Slice(seqs, func(i, j int) bool { return seqs[i] < seqs[j] })
+	return seqs
+}
+
+func (w *wal) write(rec walRecord) error {
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	_, err = w.file.Write(append(line, '\n'))
+	return err
+}
+
+// append 追加一次发送，返回它的 Seq
+func (w *wal) append(data []byte) (uint64, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.seq++
+	return w.seq, w.write(walRecord{Seq: w.seq, Data: data})
+}
+
+// ack 确认一次发送
+func (w *wal) ack(seq uint64) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	delete(w.pending, seq)
+	return w.write(walRecord{Seq: seq})
+}
+
+func (w *wal) close() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.file.Close()
+}
+
+// openWAL 打开 WithWAL 配置的日志，失败时输出日志并关闭该功能
+func (p *EventBus) openWAL() {
+	if p.wal == nil {
+		return
+	}
+	if err := p.wal.open(); err != nil {
+		p.logger.Printf("[WAL] open %s: %s", p.wal.path, err)
+		p.wal = nil
+	}
+}
+
+// logSend 入队前把发送写入日志
+func (p *EventBus) logSend(s *sender) {
+	if p.wal == nil {
+		return
+	}
+	data, err := p.Encode(s.key, s.args...)
+	if err != nil {
+		p.logger.Printf("[WAL] encode %s: %s", s.key, err)
+		return
+	}
+	if s.walSeq, err = p.wal.append(data); err != nil {
+		p.logger.Printf("[WAL] append %s: %s", s.key, err)
+		s.walSeq = 0
+	}
+}
+
+// ackSend 发送调用结束或取消后写入确认
+func (p *EventBus) ackSend(s *sender) {
+	if p.wal == nil || s.walSeq == 0 {
+		return
+	}
+	if err := p.wal.ack(s.walSeq); err != nil {
+		p.logger.Printf("[WAL] ack %s: %s", s.key, err)
+	}
+}
+
+// ReplayWAL 重新发送上次运行中写入日志但没有确认的事件，返回成功发送的个数
+// 需要在订阅注册之后调用，发送失败的事件保留在日志中，下次启动仍会回放
+func (p *EventBus) ReplayWAL() (int, error) {
+	if p.wal == nil {
+		return 0, nil
+	}
+	p.wal.mu.Lock()
+	seqs := p.wal.sortedPending()
+	pending := make([][]byte, len(seqs))
+	for i, seq := range seqs {
+		pending[i] = p.wal.pending[seq]
+	}
+	p.wal.mu.Unlock()
+	n := 0
+	var first error
+	for i, data := range pending {
+		if err := p.SendEncoded(data); err != nil {
+			if first == nil {
+				first = err
+			}
+			continue
+		}
+		// 重新发送时已经写入新的记录，旧记录可以确认
+		if err := p.wal.ack(seqs[i]); err != nil && first == nil {
+			first = err
+		}
+		n++
+	}
+	return n, first
+}
@@ -0,0 +1,70 @@
+package eventbus
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventBus_WithWAL(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.wal")
+	crashed := New(WithWAL(path))
+	done := make(chan int, 3)
+	assert.NoError(t, crashed.On("order", func(id int) { done <- id }))
+	assert.NoError(t, crashed.Send("order", 1))
+	assert.Equal(t, 1, <-done)
+	// 暂停后的发送停留在队列中，模拟进程在调用前崩溃，不调用 Close
+	crashed.Pause()
+	assert.NoError(t, crashed.Send("order", 2))
+	assert.NoError(t, crashed.Send("order", 3))
+
+	restarted := New(WithWAL(path))
+	got := make(chan int, 3)
+	assert.NoError(t, restarted.On("order", func(id int) { got <- id }))
+	n, err := restarted.ReplayWAL()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.ElementsMatch(t, []int{2, 3}, []int{<-got, <-got})
+	restarted.Close()
+
+	// 回放的事件已经确认，再次启动没有需要回放的事件
+	again := New(WithWAL(path))
+	defer again.Close()
+	assert.NoError(t, again.On("order", func(id int) { got <- id }))
+	n, err = again.ReplayWAL()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, n)
+}
+
+func TestEventBus_WithWALEncodeError(t *testing.T) {
+	events := New(WithWAL(filepath.Join(t.TempDir(), "events.wal")))
+	defer events.Close()
+	done := make(chan struct{})
+	assert.NoError(t, events.On("chan", func(ch chan int) { close(done) }))
+	// 无法编码的入参不写入日志，仍然正常调用
+	assert.NoError(t, events.Send("chan", make(chan int)))
+	<-done
+}
+
+func TestEventBus_WithWALCanceled(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.wal")
+	events := New(WithWAL(path))
+	assert.NoError(t, events.On("order", func(id int) {}))
+	events.Pause()
+	ctx, cancel := context.WithCancel(context.Background())
+	assert.NoError(t, events.SendCtx(ctx, "order", 1))
+	cancel()
+	// 排队期间取消的发送从队列中移除，同时在日志中确认
+	assert.Eventually(t, func() bool { return len(events.PendingSnapshot()) == 0 }, time.Second, time.Millisecond)
+	events.Close()
+
+	restarted := New(WithWAL(path))
+	defer restarted.Close()
+	assert.NoError(t, restarted.On("order", func(id int) {}))
+	n, err := restarted.ReplayWAL()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, n)
+}
@@ -0,0 +1,47 @@
+package eventbus
+
+import "reflect"
+
+// a callCache 反射调用需要的类型信息
+type callCache struct {
+	fn     reflect.Value
+	params []reflect.Type
+}
+
+// cached 返回调用方法的类型信息，首次使用时生成
+func (e *event) cached() *callCache {
+	e.cacheOnce.Do(func() {
+		fn := reflect.ValueOf(e.call)
+		t := fn.Type()
+		params := make([]reflect.Type, t.NumIn())
+		for i := range params {
+			params[i] = paramType(t, i)
+		}
+		e.cache = &callCache{fn: fn, params: params}
+	})
+	return e.cache
+}
+
+// param 返回第 i 个入参的类型，超出的可变参数使用元素类型
+func (c *callCache) param(i int) reflect.Type {
+	if i >= len(c.params) {
+		return c.params[len(c.params)-1]
+	}
+	return c.params[i]
+}
+
+// Warmup 使用样例入参预先生成事件全部订阅的反射类型信息，首次真正发送时不再有额外开销
+// 样例入参按发送的规则校验，事件不存在返回 ErrNotFound，个数不匹配返回 ErrArgsNotMatch
+// 订阅不会被调用，消费组内的订阅全部预热且不影响轮询，OnLazy 注册的订阅不会提前创建
+func (p *EventBus) Warmup(eventKey string, sampleArgs ...interface{}) error {
+	handlers, err := p.match(eventKey, sampleArgs, pickAll)
+	if err != nil {
+		return err
+	}
+	for _, e := range handlers {
+		if !e.isRaw() && e.lazy == nil {
+			e.cached()
+		}
+	}
+	return nil
+}
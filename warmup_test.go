@@ -0,0 +1,52 @@
+package eventbus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventBus_Warmup(t *testing.T) {
+	events := New()
+	got := make(chan int, 1)
+	assert.NoError(t, events.On("add", func(a, b int) { got <- a + b }))
+	e := events.handlers("add")[0]
+	assert.Nil(t, e.cache)
+
+	assert.EqualError(t, events.Warmup("add", 1), ErrArgsNotMatch.Error())
+	assert.EqualError(t, events.Warmup("missing"), ErrNotFound.Error())
+	assert.NoError(t, events.Warmup("add", 1, 2))
+	cache := e.cache
+	assert.NotNil(t, cache)
+	assert.Equal(t, int32(0), e.callTimes)
+
+	// 首次发送复用预先生成的类型信息
+	assert.NoError(t, events.Send("add", 1, 2))
+	assert.Equal(t, 3, <-got)
+	events.Close()
+	assert.Same(t, cache, e.cache)
+}
+
+func TestCallCache_param(t *testing.T) {
+	e := &event{call: func(s string, n ...int) {}}
+	c := e.cached()
+	assert.Equal(t, "string", c.param(0).Kind().String())
+	assert.Equal(t, "int", c.param(1).Kind().String())
+	assert.Equal(t, "int", c.param(5).Kind().String())
+}
+
+func TestWarmup_Group(t *testing.T) {
+	events := New()
+	got := make(chan string, 2)
+	assert.NoError(t, events.OnGroup("job", "workers", func(int) { got <- "a" }))
+	assert.NoError(t, events.OnGroup("job", "workers", func(int) { got <- "b" }))
+	assert.NoError(t, events.Warmup("job", 1))
+	for _, e := range events.handlers("job") {
+		assert.NotNil(t, e.cache)
+	}
+	assert.NoError(t, events.SendSync("job", 1))
+	assert.NoError(t, events.SendSync("job", 2))
+	events.Close()
+	assert.Equal(t, "a", <-got)
+	assert.Equal(t, "b", <-got)
+}
@@ -0,0 +1,103 @@
+package eventbus
+
+import (
+	"sync"
+	"time"
+)
+
+// StuckDispatch 执行时间超过限制的发送
+type StuckDispatch struct {
+	ID      uint64
+	Key     string
+	Running time.Duration
+}
+
+// minWatchInterval 检查间隔的下限，limit 过小时使用
+const minWatchInterval = time.Millisecond
+
+// a watchdog 记录正在执行的发送，定期检查执行时间
+type watchdog struct {
+	limit   time.Duration
+	onStuck func(StuckDispatch)
+	now     func() time.Time
+	mu      sync.Mutex
+	// started 正在执行的发送及开始时间
+	started map[*sender]time.Time
+	// reported 已经报告过的发送，每个发送只报告一次
+	reported map[*sender]bool
+}
+
+// WithWatchdog 检测执行时间超过 limit 的发送，通过 Logger 输出并调用 onStuck(可以为空)
+// 每个发送在 Loop 之外的协程中执行，阻塞的订阅不会卡住队列，但会一直占用协程，需要及时发现
+func WithWatchdog(limit time.Duration, onStuck func(StuckDispatch)) Option {
+	return func(p *EventBus) {
+		p.watchdog.limit = limit
+		p.watchdog.onStuck = onStuck
+	}
+}
+
+func (w *watchdog) track(s *sender) {
+	if w.limit <= 0 {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.started == nil {
+		w.started = make(map[*sender]time.Time)
+		w.reported = make(map[*sender]bool)
+	}
+	w.started[s] = w.now()
+}
+
+func (w *watchdog) untrack(s *sender) {
+	if w.limit <= 0 {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	delete(w.started, s)
+	delete(w.reported, s)
+}
+
+// stuck 返回本次新发现的执行时间超过限制的发送
+func (w *watchdog) stuck(now time.Time) []StuckDispatch {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var found []StuckDispatch
+	for s, start := range w.started {
+		if running := now.Sub(start); running >= w.limit && !w.reported[s] {
+			w.reported[s] = true
+			found = append(found, StuckDispatch{ID: s.id, Key: s.key, Running: running})
+		}
+	}
+	return found
+}
+
+// watch 按 limit 的一半定期检查正在执行的发送，直到 Loop 退出，间隔不小于 minWatchInterval
+func (p *EventBus) watch() {
+	interval := p.watchdog.limit / 2
+	if interval < minWatchInterval {
+		interval = minWatchInterval
+	}
+	tick := make(chan struct{}, 1)
+	for {
+		timer := p.clock.AfterFunc(interval, func() {
+			select {
+			case tick <- struct{}{}:
+			default:
+			}
+		})
+		select {
+		case <-p.stopped:
+			timer.Stop()
+			return
+		case <-tick:
+			for _, d := range p.watchdog.stuck(p.clock.Now()) {
+				p.logger.Printf("[WATCHDOG] send %d %s running for %s", d.ID, d.Key, d.Running)
+				if p.watchdog.onStuck != nil {
+					p.watchdog.onStuck(d)
+				}
+			}
+		}
+	}
+}
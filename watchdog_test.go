@@ -0,0 +1,65 @@
+package eventbus
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithWatchdog(t *testing.T) {
+	logger := &recordLogger{}
+	stuck := make(chan StuckDispatch, 2)
+	events := New(WithLogger(logger), WithWatchdog(20*time.Millisecond, func(d StuckDispatch) {
+		stuck <- d
+	}))
+	release := make(chan struct{})
+	assert.NoError(t, events.On("block", func() { <-release }))
+	assert.NoError(t, events.On("fast", func() {}))
+	assert.NoError(t, events.Send("fast"))
+	assert.NoError(t, events.Send("block"))
+
+	select {
+	case d := <-stuck:
+		assert.Equal(t, "block", d.Key)
+		assert.True(t, d.Running >= 20*time.Millisecond)
+	case <-time.After(time.Second):
+		t.Fatal("watchdog not fired")
+	}
+	close(release)
+	events.Close()
+
+	// 每个发送只报告一次
+	assert.Len(t, stuck, 0)
+	lines := logger.Lines()
+	if assert.Len(t, lines, 1) {
+		assert.True(t, strings.HasPrefix(lines[0], "[WATCHDOG] send 2 block"), lines[0])
+	}
+}
+
+func TestWithWatchdog_Clock(t *testing.T) {
+	clock := NewFakeClock(time.Unix(0, 0))
+	stuck := make(chan StuckDispatch, 1)
+	// limit 过小时检查间隔被放大，不会 panic
+	events := New(WithClock(clock), WithLogger(&recordLogger{}), WithWatchdog(time.Nanosecond, func(d StuckDispatch) {
+		stuck <- d
+	}))
+	release := make(chan struct{})
+	assert.NoError(t, events.On("block", func() { <-release }))
+	assert.NoError(t, events.Send("block"))
+	deadline := time.After(time.Second)
+	for done := false; !done; {
+		clock.Advance(minWatchInterval)
+		select {
+		case d := <-stuck:
+			assert.Equal(t, "block", d.Key)
+			done = true
+		case <-deadline:
+			t.Fatal("watchdog not fired")
+		case <-time.After(time.Millisecond):
+		}
+	}
+	close(release)
+	events.Close()
+}
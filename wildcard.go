@@ -0,0 +1,79 @@
+package eventbus
+
+import (
+	"context"
+	"sort"
+	"strings"
+)
+
+// OnWildcard 注册通配订阅，pattern 按 "." 分段，"*" 匹配任意一段，如 order.* 匹配 order.created
+// 调用方法的第一个参数必须是 string，接收实际发送的事件。同一通配事件允许注册多个订阅
+// 通配订阅在事件自身的订阅之后按注册顺序调用，入参个数不匹配的发送被跳过
+func (p *EventBus) OnWildcard(pattern string, call interface{}) error {
+	return p.on(&event{key: pattern, call: call, withKey: true}, false)
+}
+
+// EventKey 返回 ctx 所属发送的实际事件，通配订阅可以通过注入的 context 读取
+func EventKey(ctx context.Context) (string, bool) {
+	chain := CausationChain(ctx)
+	if len(chain) == 0 {
+		return "", false
+	}
+	return chain[len(chain)-1].Key, true
+}
+
+// isPattern 判断事件是否为通配事件，包含 "*" 的事件都按通配处理
+func isPattern(eventKey string) bool {
+	return strings.IndexByte(eventKey, '*') >= 0
+}
+
+// matchPattern 判断 eventKey 是否匹配通配事件 pattern
+func matchPattern(pattern, eventKey string) bool {
+	for {
+		pi := strings.IndexByte(pattern, '.')
+		ki := strings.IndexByte(eventKey, '.')
+		if (pi < 0) != (ki < 0) {
+			return false
+		}
+		if pi < 0 {
+			return pattern == "*" || pattern == eventKey
+		}
+		if seg := pattern[:pi]; seg != "*" && seg != eventKey[:ki] {
+			return false
+		}
+		pattern, eventKey = pattern[pi+1:], eventKey[ki+1:]
+	}
+}
+
+// indexPatterns 重建通配事件列表，调用方需持有 mu
+func (p *EventBus) indexPatterns() {
+	var patterns []string
+	p.events.Range(func(key, _ interface{}) bool {
+		if k := key.(string); isPattern(k) {
+			patterns = append(patterns, k)
+		}
+		return true
+	})
+	sort.Strings(patterns)
+	p.patterns = patterns
+}
+
+// wildcards 返回匹配 eventKey 且能够接收 args 的通配订阅，调用方需持有 mu
+func (p *EventBus) wildcards(eventKey string, args []interface{}) []*event {
+	var matched []*event
+	for _, pattern := range p.patterns {
+		if pattern == eventKey || !matchPattern(pattern, eventKey) {
+			continue
+		}
+		for _, e := range versioned(p.handlers(pattern), 0) {
+			if e.accept(args, p.ignoreExtraArgs) {
+				matched = append(matched, e)
+			}
+		}
+	}
+	// 不同通配事件的订阅也按注册顺序调用
+	sort.SliceStable(matched, func(i, j int) bool {
+		return matched[i].id < matched[j].id
+	})
+	return matched
+}
@@ -0,0 +1,50 @@
+package eventbus
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventBus_OnWildcard(t *testing.T) {
+	events := New()
+	var (
+		mu   sync.Mutex
+		seen []string
+	)
+	record := func(s string) {
+		mu.Lock()
+		seen = append(seen, s)
+		mu.Unlock()
+	}
+	assert.NoError(t, events.OnWildcard("order.*", func(key string, id int) { record("wildcard:" + key) }))
+	assert.NoError(t, events.On("order.*.done", func(ctx context.Context, id int) {
+		key, _ := EventKey(ctx)
+		record("ctx:" + key)
+	}))
+	assert.NoError(t, events.On("order.created", func(id int) { record("order.created") }))
+	assert.EqualError(t, events.OnWildcard("bad.*", func(id int) {}), ErrNotCallable.Error())
+
+	assert.NoError(t, events.Send("order.created", 1))
+	// 只有通配订阅也可以处理
+	assert.NoError(t, events.Send("order.paid", 2))
+	assert.NoError(t, events.Send("order.paid.done", 3))
+	assert.EqualError(t, events.Send("user.login", 4), ErrNotFound.Error())
+	events.Close()
+
+	assert.ElementsMatch(t, []string{"order.created", "wildcard:order.created", "wildcard:order.paid", "ctx:order.paid.done"}, seen)
+
+	events.Remove("order.*")
+	assert.Equal(t, []string{"order.*.done"}, events.patterns)
+}
+
+func TestMatchPattern(t *testing.T) {
+	assert.True(t, matchPattern("order.*", "order.created"))
+	assert.True(t, matchPattern("*.created", "order.created"))
+	assert.True(t, matchPattern("*", "order"))
+	assert.False(t, matchPattern("order.*", "order"))
+	assert.False(t, matchPattern("order.*", "order.item.created"))
+	assert.False(t, matchPattern("order.*", "user.created"))
+}